@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"maga-auctions/entity"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeETag sends the version of the vehicle as a strong ETag
+func writeETag(c *gin.Context, v entity.Vehicle) {
+	c.Header("ETag", `"`+v.Version()+`"`)
+}
+
+// ifMatch reads the version the client expects, empty when any version is accepted
+func ifMatch(c *gin.Context) string {
+	tag := strings.TrimSpace(c.GetHeader("If-Match"))
+
+	if tag == "*" {
+		return ""
+	}
+
+	return strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"maga-auctions/entity"
+	"maga-auctions/legacy"
 	"maga-auctions/vehicle"
 	"time"
 
@@ -15,13 +16,17 @@ type HealthCheck interface {
 }
 
 type healthCheck struct {
-	srv vehicle.Service
+	srv     vehicle.Service
+	breaker legacy.Breaker
+	cache   legacy.Cache
 }
 
-// NewHealthCheck controller
-func NewHealthCheck(srv vehicle.Service) HealthCheck {
+// NewHealthCheck controller, breaker and cache may be nil when the legacy api has no circuit breaker or cache
+func NewHealthCheck(srv vehicle.Service, breaker legacy.Breaker, cache legacy.Cache) HealthCheck {
 	return &healthCheck{
-		srv: srv,
+		srv:     srv,
+		breaker: breaker,
+		cache:   cache,
 	}
 }
 
@@ -30,12 +35,15 @@ func (h healthCheck) HealthCheck(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	_, err := h.srv.ByID(ctx, 1)
+	ctx, report := legacy.WithReport(ctx)
+
+	_, err := h.srv.All(ctx, nil, "")
+	stale, _ := report.Stale()
 
 	s := "ok"
 	code := 200
 
-	if err != nil {
+	if err != nil || stale {
 		s = "error"
 		code = 500
 	}
@@ -45,6 +53,19 @@ func (h healthCheck) HealthCheck(c *gin.Context) {
 		Dependencies: map[string]string{
 			"legacyApi": s,
 		},
+		Counters: map[string]uint64{
+			"legacyDecodeAnomalies": legacy.DecodeAnomalies(),
+		},
+	}
+
+	if h.breaker != nil {
+		hc.Dependencies["legacyApiCircuit"] = h.breaker.State()
+	}
+
+	if h.cache != nil {
+		stats := h.cache.Stats()
+		hc.Counters["legacyCacheHits"] = stats.Hits
+		hc.Counters["legacyCacheMisses"] = stats.Misses
 	}
 
 	c.JSON(code, hc)
@@ -1,6 +1,10 @@
 package controller_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"maga-auctions/api/controller"
 	"maga-auctions/legacy"
 	mock_legacy "maga-auctions/legacy/mocks"
@@ -8,7 +12,9 @@ import (
 	"maga-auctions/vehicle"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +28,21 @@ func mockApiLegacy(pathJSON string, statusCode int) {
 	}
 }
 
+// mockApiLegacyWrite answers the write with pathJSON and the read that checks the control codes with the dataset
+func mockApiLegacyWrite(pathJSON string, statusCode int) {
+	legacy.APIURI = "https://test.com"
+	legacy.Client = &mock_legacy.MockClient{}
+	mock_legacy.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+		b, _ := ioutil.ReadAll(req.Body)
+
+		if strings.Contains(string(b), `"OPERACAO":"consultar"`) {
+			return &http.Response{Body: utils.TestMakeBody("testdata/consultar_response_api.json"), StatusCode: 200}, nil
+		}
+
+		return &http.Response{Body: utils.TestMakeBody(pathJSON), StatusCode: statusCode}, nil
+	}
+}
+
 func TestHealthCheck(t *testing.T) {
 	t.Run("must return status ok", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -31,10 +52,10 @@ func TestHealthCheck(t *testing.T) {
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
 
-		controller.NewHealthCheck(srv).HealthCheck(c)
+		controller.NewHealthCheck(srv, nil, nil).HealthCheck(c)
 
 		assert.Equal(t, 200, w.Code)
-		assert.JSONEq(t, `{"status":"ok","dependencies":{"legacyApi":"ok"}}`, w.Body.String())
+		assert.JSONEq(t, fmt.Sprintf(`{"status":"ok","dependencies":{"legacyApi":"ok"},"counters":{"legacyDecodeAnomalies":%d}}`, legacy.DecodeAnomalies()), w.Body.String())
 	})
 }
 
@@ -47,9 +68,80 @@ func TestHealthCheck_Errors(t *testing.T) {
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
 
-		controller.NewHealthCheck(srv).HealthCheck(c)
+		controller.NewHealthCheck(srv, nil, nil).HealthCheck(c)
+
+		assert.Equal(t, 500, w.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"status":"error","dependencies":{"legacyApi":"error"},"counters":{"legacyDecodeAnomalies":%d}}`, legacy.DecodeAnomalies()), w.Body.String())
+	})
+}
+
+func TestHealthCheck_Breaker(t *testing.T) {
+	t.Run("must report the circuit state", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		breaker := legacy.NewBreaker(legacy.NewAPI(), legacy.BreakerPolicy{FailureThreshold: 1, CoolDown: time.Minute})
+		srv := vehicle.NewService(breaker)
+
+		controller.NewHealthCheck(srv, breaker, nil).HealthCheck(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"status":"ok","dependencies":{"legacyApi":"ok","legacyApiCircuit":"closed"},"counters":{"legacyDecodeAnomalies":%d}}`, legacy.DecodeAnomalies()), w.Body.String())
+	})
+
+	t.Run("must report error while serving stale data", func(t *testing.T) {
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		breaker := legacy.NewBreaker(legacy.NewAPI(), legacy.BreakerPolicy{FailureThreshold: 1, CoolDown: time.Minute})
+		srv := vehicle.NewService(breaker)
+		_, _ = breaker.Get(context.Background())
+
+		mockApiLegacy("", 503)
+		_, _ = breaker.Get(context.Background())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		controller.NewHealthCheck(srv, breaker, nil).HealthCheck(c)
 
 		assert.Equal(t, 500, w.Code)
-		assert.JSONEq(t, `{"status":"error","dependencies":{"legacyApi":"error"}}`, w.Body.String())
+		assert.JSONEq(t, fmt.Sprintf(`{"status":"error","dependencies":{"legacyApi":"error","legacyApiCircuit":"open"},"counters":{"legacyDecodeAnomalies":%d}}`, legacy.DecodeAnomalies()), w.Body.String())
+	})
+}
+
+func TestHealthCheck_Cache(t *testing.T) {
+	t.Run("must report the cache counters", func(t *testing.T) {
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+		cache := legacy.NewCache(legacy.NewAPI(), time.Minute)
+		srv := vehicle.NewService(cache)
+		_, _ = cache.Get(context.Background())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		controller.NewHealthCheck(srv, nil, cache).HealthCheck(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"status":"ok","dependencies":{"legacyApi":"ok"},"counters":{"legacyCacheHits":1,"legacyCacheMisses":1,"legacyDecodeAnomalies":%d}}`, legacy.DecodeAnomalies()), w.Body.String())
+	})
+}
+
+func TestHealthCheck_Anomalies(t *testing.T) {
+	t.Run("must count the degraded legacy rows", func(t *testing.T) {
+		mockApiLegacy("../../legacy/testdata/consultar_response_api.json", 200)
+		before := legacy.DecodeAnomalies()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		controller.NewHealthCheck(vehicle.NewService(legacy.NewAPI()), nil, nil).HealthCheck(c)
+
+		var hc struct {
+			Counters map[string]uint64 `json:"counters"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &hc)
+
+		assert.Equal(t, before+1, hc.Counters["legacyDecodeAnomalies"])
 	})
 }
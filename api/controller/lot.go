@@ -2,41 +2,329 @@ package controller
 
 import (
 	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
 	"maga-auctions/api/handler"
+	"maga-auctions/legacy"
+	"maga-auctions/lot"
 	"maga-auctions/vehicle"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+type lotResponse struct {
+	ID               string      `json:"id"`
+	Status           string      `json:"status"`
+	StartsAt         *time.Time  `json:"startsAt,omitempty"`
+	EndsAt           *time.Time  `json:"endsAt,omitempty"`
+	ScheduledEndAt   *time.Time  `json:"scheduledEndAt,omitempty"`
+	SettledAt        *time.Time  `json:"settledAt,omitempty"`
+	RemainingSeconds int64       `json:"remainingSeconds"`
+	Events           []lot.Event `json:"events,omitempty"`
+}
+
+type scheduleRequest struct {
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
 // LotController contract
 type LotController interface {
+	All(c *gin.Context)
 	VehiclesByLot(c *gin.Context)
+	VehicleByLotID(c *gin.Context)
+	ByID(c *gin.Context)
+	Schedule(c *gin.Context)
+	Close(c *gin.Context)
+	Results(c *gin.Context)
 }
 
 type lotCtrl struct {
-	srv vehicle.Service
+	srv  vehicle.Service
+	lots lot.Service
 }
 
 // NewLot controller
-func NewLot(srv vehicle.Service) LotController {
+func NewLot(srv vehicle.Service, lots lot.Service) LotController {
 	return &lotCtrl{
-		srv: srv,
+		srv:  srv,
+		lots: lots,
 	}
 }
 
+func (v lotCtrl) All(c *gin.Context) {
+	page, err := buildPage(c)
+
+	if err != nil {
+		handler.ResponseError(handler.BadRequest{Message: err.Error()}, c)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
+
+	lots, err := v.lots.All(ctx, v.srv, lot.ListQuery{Sort: c.Query("sort"), Page: page})
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	writeReport(c, report)
+
+	handler.ResponseSuccess(200, lots, c)
+}
+
 func (v lotCtrl) VehiclesByLot(c *gin.Context) {
 	id := c.Param("id")
+	query, err := buildPageQuery(c)
+
+	if err != nil {
+		handler.ResponseError(handler.BadRequest{Message: err.Error()}, c)
+		return
+	}
+
+	order, err := buildOrder(c)
+
+	if err != nil {
+		handler.ResponseError(handler.BadRequest{Message: err.Error()}, c)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
+
+	page, err := v.srv.ListByLot(ctx, id, order, query)
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	writeReport(c, report)
+	writeLinks(c, page)
+
+	handler.ResponseSuccess(200, vehiclePageResponse{VehiclePage: page, Warnings: report.Warnings()}, c)
+}
+
+func (v lotCtrl) VehicleByLotID(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
+
+	ve, err := v.srv.ByVehicleLotID(ctx, c.Param("id"), c.Param("vehicleLotId"))
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	writeReport(c, report)
+	writeETag(c, *ve)
+
+	uri := c.Request.RequestURI
+
+	if i := strings.Index(uri, "/lots/"); i >= 0 {
+		uri = uri[:i]
+	}
+
+	res := response{
+		Vehicle: *ve,
+		Links: []Link{
+			{
+				Relation:     "vehicle",
+				RelationType: "GET",
+				URI:          fmt.Sprintf("%s/vehicles/%d", uri, ve.ID),
+			},
+		},
+	}
+
+	handler.ResponseSuccess(200, res, c)
+}
+
+func (v lotCtrl) ByID(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
+
+	state, err := v.lots.ByID(ctx, id)
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	if !state.Scheduled() {
+		if err := v.exists(ctx, id); err != nil {
+			handler.ResponseError(err, c)
+			return
+		}
+	}
+
+	writeReport(c, report)
+
+	handler.ResponseSuccess(200, newLotResponse(state), c)
+}
+
+func (v lotCtrl) Schedule(c *gin.Context) {
+	id := c.Param("id")
+
+	var req scheduleRequest
+	err := c.BindJSON(&req)
+
+	if err != nil {
+		handler.ResponseError(
+			handler.BadRequest{
+				Message: "body is invalid",
+			},
+			c,
+		)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	vs, err := v.srv.ByLotID(ctx, id, c.Query("bidOrder"))
+	if err := v.exists(ctx, id); err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	state, err := v.lots.Schedule(ctx, id, req.StartsAt, req.EndsAt)
 
 	if err != nil {
 		handler.ResponseError(err, c)
 		return
 	}
 
-	handler.ResponseSuccess(200, vs, c)
+	handler.ResponseSuccess(200, newLotResponse(state), c)
+}
+
+func (v lotCtrl) Close(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	if err := v.exists(ctx, id); err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	results, err := v.lots.Close(ctx, id, v.srv)
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.RequestURI, "/close")+"/results")
+
+	handler.ResponseSuccess(200, results, c)
+}
+
+func (v lotCtrl) Results(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	results, err := v.lots.Results(ctx, c.Param("id"))
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	if c.Query("format") != "csv" && c.NegotiateFormat(gin.MIMEJSON, "text/csv") != "text/csv" {
+		handler.ResponseSuccess(200, results, c)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="lot-%s-results.csv"`, results.LotID))
+	c.Status(200)
+	c.Writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+	if err := writeResultsCSV(c.Writer, results); err != nil {
+		log.Print(err)
+	}
+}
+
+// writeResultsCSV writes one row per vehicle of the lot
+func writeResultsCSV(w io.Writer, r *lot.Results) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{{"vehicleId", "vehicleLotId", "brand", "model", "sold", "winner", "hammerPrice", "bidAt"}}
+
+	for _, item := range r.Items {
+		bidAt := ""
+
+		if item.BidAt != nil {
+			bidAt = item.BidAt.Format(time.RFC3339)
+		}
+
+		rows = append(rows, []string{
+			strconv.Itoa(item.VehicleID),
+			csvCell(item.VehicleLotID),
+			csvCell(item.Brand),
+			csvCell(item.Model),
+			strconv.FormatBool(item.Sold),
+			csvCell(item.Winner),
+			item.HammerPrice.String(),
+			bidAt,
+		})
+	}
+
+	return out.WriteAll(rows)
+}
+
+// csvCell quotes the texts a spreadsheet would run as a formula
+func csvCell(s string) string {
+	if s != "" && strings.ContainsAny(s[:1], "=+-@\t\r") {
+		return "'" + s
+	}
+
+	return s
+}
+
+// exists checks the lot has vehicles in the legacy api
+func (v lotCtrl) exists(ctx context.Context, id string) error {
+	vs, err := v.srv.ByLotID(ctx, id, "")
+
+	if err != nil {
+		return err
+	}
+
+	if len(*vs) == 0 {
+		return handler.NotFound{Message: "lot not found"}
+	}
+
+	return nil
+}
+
+func newLotResponse(s *lot.State) lotResponse {
+	res := lotResponse{
+		ID:               s.LotID,
+		Status:           s.Status,
+		RemainingSeconds: int64(s.Remaining / time.Second),
+		Events:           s.Events,
+	}
+
+	if s.Scheduled() {
+		res.StartsAt = &s.StartsAt
+		res.EndsAt = &s.EndsAt
+		res.ScheduledEndAt = &s.ScheduledEndAt
+	}
+
+	if !s.SettledAt.IsZero() {
+		res.SettledAt = &s.SettledAt
+	}
+
+	return res
 }
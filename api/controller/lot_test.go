@@ -1,17 +1,77 @@
 package controller_test
 
 import (
+	"bytes"
 	"maga-auctions/api/controller"
+	"maga-auctions/api/helper/paging"
 	"maga-auctions/legacy"
+	"maga-auctions/lot"
 	"maga-auctions/vehicle"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestAllLots(t *testing.T) {
+	t.Run("must list the lots with their bids", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/lots?sort=-vehicles&pageSize=2", nil)
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		srv := vehicle.NewService(legacy.NewAPI())
+
+		controller.NewLot(srv, lot.NewService()).All(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(
+			t,
+			`{"items":[{"id":"9999","status":"open","vehicles":68,"highestBid":70000,"lowestBid":400,"lastBidAt":"2020-09-16T12:15:00Z","bidders":53},{"id":"0001","status":"open","vehicles":4,"highestBid":26500,"lowestBid":8500,"lastBidAt":"2020-08-29T09:15:00Z","bidders":3}],"page":1,"pageSize":2,"total":410}`,
+			w.Body.String(),
+		)
+	})
+}
+
+func TestAllLots_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, query, wantJson string
+		wantStatus            int
+	}{
+		{
+			desc:       "must return error when page is invalid",
+			query:      "/lots?page=a",
+			wantStatus: 400,
+			wantJson:   `{"error":"page is invalid"}`,
+		},
+		{
+			desc:       "must return error when the sort field is unknown",
+			query:      "/lots?sort=brand",
+			wantStatus: 400,
+			wantJson:   `{"error":"lots cannot be sorted by \"brand\""}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", tt.query, nil)
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			srv := vehicle.NewService(legacy.NewAPI())
+
+			controller.NewLot(srv, lot.NewService()).All(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
 func TestVehiclesByLot(t *testing.T) {
 	t.Run("must return status ok", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -23,12 +83,69 @@ func TestVehiclesByLot(t *testing.T) {
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
 
-		controller.NewLot(srv).VehiclesByLot(c)
+		controller.NewLot(srv, lot.NewService()).VehiclesByLot(c)
 
 		assert.Equal(t, 200, w.Code)
 		assert.JSONEq(
 			t,
-			`[{"id":180,"brand":"HONDA","model":"CIVIC SEDAN LXR","modelYear":2015,"manufacturingYear":2014,"lot":{"id":"0161","vehicleLotId":"733135"},"bid":{"date":"2020-08-21T12:58:00Z","value":5500,"user":"Michaelnf"}},{"id":725,"brand":"FIAT","model":"STRADA ADVENTURE CD","modelYear":2010,"manufacturingYear":2010,"lot":{"id":"0161","vehicleLotId":"733577"},"bid":{"date":"2020-08-22T11:15:00Z","value":22500,"user":"Damião A. d. S."}}]`,
+			`{"items":[{"id":180,"brand":"HONDA","model":"CIVIC SEDAN LXR","modelYear":2015,"manufacturingYear":2014,"lot":{"id":"0161","vehicleLotId":"733135"},"bid":{"date":"2020-08-21T12:58:00Z","value":5500,"user":"Michaelnf"},"reserveMet":true},{"id":725,"brand":"FIAT","model":"STRADA ADVENTURE CD","modelYear":2010,"manufacturingYear":2010,"lot":{"id":"0161","vehicleLotId":"733577"},"bid":{"date":"2020-08-22T11:15:00Z","value":22500,"user":"Damião A. d. S."},"reserveMet":true}],"total":2,"page":1,"pageSize":20}`,
+			w.Body.String(),
+		)
+	})
+}
+
+func TestVehiclesByLot_Pages(t *testing.T) {
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "0161"}}
+		c.Request, _ = http.NewRequest("GET", "/lots/0161/vehicles"+query, nil)
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		controller.NewLot(vehicle.NewService(legacy.NewAPI()), lot.NewService()).VehiclesByLot(c)
+
+		return w
+	}
+	cursor := paging.Cursor{Offset: 1, Key: "180"}.Encode()
+
+	t.Run("must link the pages by number", func(t *testing.T) {
+		w := get("?pageSize=1&bidOrder=asc")
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `</lots/0161/vehicles?bidOrder=asc&page=1&pageSize=1>; rel="first", </lots/0161/vehicles?bidOrder=asc&page=2&pageSize=1>; rel="next", </lots/0161/vehicles?bidOrder=asc&page=2&pageSize=1>; rel="last"`, w.Header().Get("Link"))
+		assert.JSONEq(
+			t,
+			`{"items":[{"id":180,"brand":"HONDA","model":"CIVIC SEDAN LXR","modelYear":2015,"manufacturingYear":2014,"lot":{"id":"0161","vehicleLotId":"733135"},"bid":{"date":"2020-08-21T12:58:00Z","value":5500,"user":"Michaelnf"},"reserveMet":true}],"total":2,"page":1,"pageSize":1,"nextCursor":"`+cursor+`"}`,
+			w.Body.String(),
+		)
+
+		w = get("?pageSize=1&page=2")
+		assert.Equal(t, `</lots/0161/vehicles?page=1&pageSize=1>; rel="first", </lots/0161/vehicles?page=1&pageSize=1>; rel="prev", </lots/0161/vehicles?page=2&pageSize=1>; rel="last"`, w.Header().Get("Link"))
+	})
+
+	t.Run("must page the sorted vehicles", func(t *testing.T) {
+		w := get("?pageSize=1&sort=-bid.value,brand")
+
+		assert.Equal(t, 200, w.Code)
+		assert.Contains(t, w.Body.String(), `"items":[{"id":725,`)
+		assert.Contains(t, w.Header().Get("Link"), `</lots/0161/vehicles?page=2&pageSize=1&sort=-bid.value%2Cbrand>; rel="next"`)
+	})
+
+	t.Run("must return an empty page for the max page number", func(t *testing.T) {
+		w := get("?page=9223372036854775807")
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"items":[],"total":2,"page":9223372036854775807,"pageSize":20}`, w.Body.String())
+	})
+
+	t.Run("must follow the cursor", func(t *testing.T) {
+		w := get("?pageSize=1&cursor=" + cursor)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `</lots/0161/vehicles?page=1&pageSize=1>; rel="first"`, w.Header().Get("Link"))
+		assert.JSONEq(
+			t,
+			`{"items":[{"id":725,"brand":"FIAT","model":"STRADA ADVENTURE CD","modelYear":2010,"manufacturingYear":2010,"lot":{"id":"0161","vehicleLotId":"733577"},"bid":{"date":"2020-08-22T11:15:00Z","value":22500,"user":"Damião A. d. S."},"reserveMet":true}],"total":2,"pageSize":1}`,
 			w.Body.String(),
 		)
 	})
@@ -58,7 +175,7 @@ func TestVehiclesByLot_Errors(t *testing.T) {
 			api := legacy.NewAPI()
 			srv := vehicle.NewService(api)
 
-			controller.NewLot(srv).VehiclesByLot(c)
+			controller.NewLot(srv, lot.NewService()).VehiclesByLot(c)
 
 			assert.Equal(t, tt.wantStatus, w.Code)
 			assert.JSONEq(
@@ -69,3 +186,287 @@ func TestVehiclesByLot_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestLotByID(t *testing.T) {
+	now := time.Date(2020, 8, 28, 9, 30, 0, 0, time.UTC)
+	clock := lot.WithClock(func() time.Time { return now })
+
+	testCases := []struct {
+		desc, id, wantJson string
+		schedule           bool
+		wantStatus         int
+	}{
+		{
+			desc:       "must keep a lot without schedule open",
+			id:         "0161",
+			wantStatus: 200,
+			wantJson:   `{"id":"0161","status":"open","remainingSeconds":0}`,
+		},
+		{
+			desc:       "must report the status and time remaining",
+			id:         "0161",
+			schedule:   true,
+			wantStatus: 200,
+			wantJson:   `{"id":"0161","status":"open","startsAt":"2020-08-28T09:00:00Z","endsAt":"2020-08-28T10:00:00Z","scheduledEndAt":"2020-08-28T10:00:00Z","remainingSeconds":1800,"events":[{"kind":"scheduled","at":"2020-08-28T09:30:00Z","status":"open","endsAt":"2020-08-28T10:00:00Z"}]}`,
+		},
+		{
+			desc:       "must return error when lot is not found",
+			id:         "0999",
+			wantStatus: 404,
+			wantJson:   `{"error":"lot not found"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			srv := vehicle.NewService(legacy.NewAPI())
+			lots := lot.NewService(clock)
+
+			if tt.schedule {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+				c.Request, _ = http.NewRequest("PUT", "/lots/"+tt.id+"/schedule", bytes.NewBufferString(`{"startsAt":"2020-08-28T09:00:00Z","endsAt":"2020-08-28T10:00:00Z"}`))
+
+				controller.NewLot(srv, lots).Schedule(c)
+
+				assert.Equal(t, 200, w.Code)
+				assert.JSONEq(t, tt.wantJson, w.Body.String())
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+			c.Request, _ = http.NewRequest("GET", "/lots/"+tt.id, nil)
+
+			controller.NewLot(srv, lots).ByID(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
+func TestSchedule_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, id, body, wantJson string
+		wantStatus               int
+	}{
+		{
+			desc:       "must return error when body is invalid",
+			id:         "0161",
+			body:       `{"startsAt":"28/08/2020"}`,
+			wantStatus: 400,
+			wantJson:   `{"error":"body is invalid"}`,
+		},
+		{
+			desc:       "must return error when lot is not found",
+			id:         "0999",
+			body:       `{"startsAt":"2020-08-28T09:00:00Z","endsAt":"2020-08-28T10:00:00Z"}`,
+			wantStatus: 404,
+			wantJson:   `{"error":"lot not found"}`,
+		},
+		{
+			desc:       "must return error when lot ends before it starts",
+			id:         "0161",
+			body:       `{"startsAt":"2020-08-28T10:00:00Z","endsAt":"2020-08-28T09:00:00Z"}`,
+			wantStatus: 400,
+			wantJson:   `{"error":"lot must end after it starts"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+			c.Request, _ = http.NewRequest("PUT", "/lots/"+tt.id+"/schedule", bytes.NewBufferString(tt.body))
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			srv := vehicle.NewService(legacy.NewAPI())
+
+			controller.NewLot(srv, lot.NewService()).Schedule(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
+func TestCloseAndResults(t *testing.T) {
+	now := time.Date(2020, 8, 28, 9, 30, 0, 0, time.UTC)
+	mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+	srv := vehicle.NewService(legacy.NewAPI())
+	lots := lot.NewService(lot.WithClock(func() time.Time { return now }))
+	results := `{"lotId":"0161","closedAt":"2020-08-28T09:30:00Z","settledAt":"2020-08-28T09:30:00Z","items":[{"vehicleId":180,"vehicleLotId":"733135","brand":"HONDA","model":"CIVIC SEDAN LXR","sold":true,"winner":"Michaelnf","hammerPrice":5500,"bidAt":"2020-08-21T12:58:00Z"},{"vehicleId":725,"vehicleLotId":"733577","brand":"FIAT","model":"STRADA ADVENTURE CD","sold":true,"winner":"Damião A. d. S.","hammerPrice":22500,"bidAt":"2020-08-22T11:15:00Z"}]}`
+
+	t.Run("must close the lot", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "0161"}}
+		c.Request, _ = http.NewRequest("POST", "/lots/0161/close", nil)
+		c.Request.RequestURI = "/lots/0161/close"
+
+		controller.NewLot(srv, lots).Close(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "/lots/0161/results", w.Header().Get("Location"))
+		assert.JSONEq(t, results, w.Body.String())
+	})
+
+	t.Run("must return the results as json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "0161"}}
+		c.Request, _ = http.NewRequest("GET", "/lots/0161/results", nil)
+
+		controller.NewLot(srv, lots).Results(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, results, w.Body.String())
+	})
+
+	t.Run("must return the results as csv", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "0161"}}
+		c.Request, _ = http.NewRequest("GET", "/lots/0161/results?format=csv", nil)
+
+		controller.NewLot(srv, lots).Results(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="lot-0161-results.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(
+			t,
+			"vehicleId,vehicleLotId,brand,model,sold,winner,hammerPrice,bidAt\n"+
+				"180,733135,HONDA,CIVIC SEDAN LXR,true,Michaelnf,5500.00,2020-08-21T12:58:00Z\n"+
+				"725,733577,FIAT,STRADA ADVENTURE CD,true,Damião A. d. S.,22500.00,2020-08-22T11:15:00Z\n",
+			w.Body.String(),
+		)
+	})
+
+	t.Run("must return error when the lot is closed again", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "0161"}}
+		c.Request, _ = http.NewRequest("POST", "/lots/0161/close", nil)
+
+		controller.NewLot(srv, lots).Close(c)
+
+		assert.Equal(t, 409, w.Code)
+		assert.JSONEq(t, `{"error":"lot 0161 is settled"}`, w.Body.String())
+	})
+}
+
+func TestResults_CSVFormula(t *testing.T) {
+	mockApiLegacy("testdata/consultar_formula_response_api.json", 200)
+
+	srv := vehicle.NewService(legacy.NewAPI())
+	lots := lot.NewService()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "0900"}}
+	c.Request, _ = http.NewRequest("POST", "/lots/0900/close", nil)
+	controller.NewLot(srv, lots).Close(c)
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "0900"}}
+	c.Request, _ = http.NewRequest("GET", "/lots/0900/results?format=csv", nil)
+	controller.NewLot(srv, lots).Results(c)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(
+		t,
+		"vehicleId,vehicleLotId,brand,model,sold,winner,hammerPrice,bidAt\n"+
+			`900,'+900001,"'=HYPERLINK(""http://evil.test"")",'@SUM(1+1),true,'-cmd,5500.00,2020-08-21T12:58:00Z`+"\n",
+		w.Body.String(),
+	)
+}
+func TestCloseAndResults_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, id, wantJson string
+		close              bool
+		wantStatus         int
+	}{
+		{
+			desc:       "must return error when closing a lot that is not found",
+			id:         "0999",
+			close:      true,
+			wantStatus: 404,
+			wantJson:   `{"error":"lot not found"}`,
+		},
+		{
+			desc:       "must return error when the lot has no results",
+			id:         "0161",
+			wantStatus: 404,
+			wantJson:   `{"error":"lot results not found"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+			c.Request, _ = http.NewRequest("GET", "/lots/"+tt.id, nil)
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			ctrl := controller.NewLot(vehicle.NewService(legacy.NewAPI()), lot.NewService())
+
+			if tt.close {
+				ctrl.Close(c)
+			} else {
+				ctrl.Results(c)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
+func TestVehicleByLotID(t *testing.T) {
+	testCases := []struct {
+		desc, id, vehicleLotID, wantJson string
+		wantStatus                       int
+	}{
+		{
+			desc:         "must resolve the control code to the vehicle",
+			id:           "0161",
+			vehicleLotID: "733135",
+			wantStatus:   200,
+			wantJson:     `{"vehicle":{"id":180,"brand":"HONDA","model":"CIVIC SEDAN LXR","modelYear":2015,"manufacturingYear":2014,"lot":{"id":"0161","vehicleLotId":"733135"},"bid":{"date":"2020-08-21T12:58:00Z","value":5500,"user":"Michaelnf"},"reserveMet":true},"links":[{"uri":"/maga-auctions/v1/vehicles/180","rel":"vehicle","type":"GET"}]}`,
+		},
+		{
+			desc:         "must return error when the control code is not in the lot",
+			id:           "0999",
+			vehicleLotID: "733135",
+			wantStatus:   404,
+			wantJson:     `{"error":"vehicle not found"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}, {Key: "vehicleLotId", Value: tt.vehicleLotID}}
+			uri := "/maga-auctions/v1/lots/" + tt.id + "/vehicles/" + tt.vehicleLotID
+			c.Request, _ = http.NewRequest("GET", uri, nil)
+			c.Request.RequestURI = uri
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			controller.NewLot(vehicle.NewService(legacy.NewAPI()), lot.NewService()).VehicleByLotID(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"fmt"
+	"maga-auctions/vehicle"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeLinks tells the client in a RFC 8288 Link header where the other pages are
+func writeLinks(c *gin.Context, page *vehicle.VehiclePage) {
+	links := []string{}
+	link := func(rel string, set map[string]string) {
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, pageURI(c, set), rel))
+	}
+	size := strconv.Itoa(page.PageSize)
+
+	link("first", map[string]string{"page": "1", "pageSize": size})
+
+	if page.Page == 0 {
+		if page.NextCursor != "" {
+			link("next", map[string]string{"cursor": page.NextCursor, "pageSize": size})
+		}
+
+		c.Header("Link", strings.Join(links, ", "))
+		return
+	}
+
+	last := (page.Total + page.PageSize - 1) / page.PageSize
+
+	if last < 1 {
+		last = 1
+	}
+
+	if page.Page > 1 {
+		link("prev", map[string]string{"page": strconv.Itoa(page.Page - 1), "pageSize": size})
+	}
+
+	if page.Page < last {
+		link("next", map[string]string{"page": strconv.Itoa(page.Page + 1), "pageSize": size})
+	}
+
+	link("last", map[string]string{"page": strconv.Itoa(last), "pageSize": size})
+
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// pageURI is the requested uri with the paging params replaced, the other params are kept
+func pageURI(c *gin.Context, set map[string]string) string {
+	q := c.Request.URL.Query()
+	q.Del("page")
+	q.Del("cursor")
+
+	for k, v := range set {
+		q.Set(k, v)
+	}
+
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: q.Encode()}
+
+	return u.String()
+}
@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"maga-auctions/legacy"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeReport tells the client in the headers how the legacy data was served
+func writeReport(c *gin.Context, report *legacy.Report) {
+	if stale, since := report.Stale(); stale {
+		c.Header("Warning", `110 - "Response is Stale"`)
+		c.Header("Age", strconv.Itoa(int(time.Since(since).Seconds())))
+	}
+}
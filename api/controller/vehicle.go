@@ -6,9 +6,14 @@ import (
 	"fmt"
 	"maga-auctions/api/handler"
 	"maga-auctions/api/helper/filters"
+	"maga-auctions/api/helper/paging"
+	"maga-auctions/api/helper/query"
+	"maga-auctions/api/helper/validation"
 	"maga-auctions/entity"
+	"maga-auctions/legacy"
 	"maga-auctions/vehicle"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +24,28 @@ type response struct {
 	Links   []Link         `json:"links"`
 }
 
+// vehicleRequest is the vehicle written by the seller, the reserve price is kept but never answered
+type vehicleRequest struct {
+	entity.Vehicle
+	ReservePrice *entity.Money `json:"reservePrice"`
+}
+
+type bidRequest struct {
+	User  string       `json:"user"`
+	Value entity.Money `json:"value"`
+}
+
+type maxBidRequest struct {
+	User string       `json:"user"`
+	Max  entity.Money `json:"max"`
+}
+
+// vehiclePageResponse is a page of vehicles with the warnings of the legacy data
+type vehiclePageResponse struct {
+	*vehicle.VehiclePage
+	Warnings []legacy.Warning `json:"warnings,omitempty"`
+}
+
 type Link struct {
 	URI          string `json:"uri"`
 	Relation     string `json:"rel"`
@@ -28,10 +55,15 @@ type Link struct {
 // VehicleController contract
 type VehicleController interface {
 	Create(c *gin.Context)
+	Validate(c *gin.Context)
 	All(c *gin.Context)
 	ByID(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
+	Bids(c *gin.Context)
+	PlaceBid(c *gin.Context)
+	SetMaxBid(c *gin.Context)
+	BidsByUser(c *gin.Context)
 }
 
 type vehicleCtrl struct {
@@ -46,8 +78,8 @@ func NewVehicle(srv vehicle.Service) VehicleController {
 }
 
 func (v vehicleCtrl) Create(c *gin.Context) {
-	var ve entity.Vehicle
-	err := c.BindJSON(&ve)
+	var req vehicleRequest
+	err := c.BindJSON(&req)
 
 	if err != nil {
 		handler.ResponseError(
@@ -59,6 +91,14 @@ func (v vehicleCtrl) Create(c *gin.Context) {
 		return
 	}
 
+	ve := req.Vehicle
+	ve.Reserve = req.ReservePrice
+
+	if err := validation.Vehicle(ve); err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
@@ -96,6 +136,32 @@ func (v vehicleCtrl) Create(c *gin.Context) {
 	handler.ResponseSuccess(201, res, c)
 }
 
+// Validate checks the vehicle with the rules of create and update without writing it
+func (v vehicleCtrl) Validate(c *gin.Context) {
+	var req vehicleRequest
+	err := c.BindJSON(&req)
+
+	if err != nil {
+		handler.ResponseError(
+			handler.BadRequest{
+				Message: "body is invalid",
+			},
+			c,
+		)
+		return
+	}
+
+	ve := req.Vehicle
+	ve.Reserve = req.ReservePrice
+
+	if err := validation.Vehicle(ve); err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	handler.ResponseSuccess(200, gin.H{"valid": true}, c)
+}
+
 func buildFilters(c *gin.Context, fs *[]filters.Filter) error {
 	fb := c.Query("brand")
 	if fb != "" {
@@ -127,6 +193,89 @@ func buildFilters(c *gin.Context, fs *[]filters.Filter) error {
 		*fs = append(*fs, f)
 	}
 
+	if rm := c.Query("reserveMet"); rm != "" {
+		met, err := strconv.ParseBool(rm)
+		if err != nil {
+			return errors.New("reserve met is invalid")
+		}
+
+		*fs = append(*fs, filters.NewVehicleReserveMet(met))
+	}
+
+	vMin, err := buildMoney(c.Query("bidValueMin"))
+	if err != nil {
+		return errors.New("bid value min is invalid")
+	}
+
+	vMax, err := buildMoney(c.Query("bidValueMax"))
+	if err != nil {
+		return errors.New("bid value max is invalid")
+	}
+
+	if vMin != nil || vMax != nil {
+		f, err := filters.NewVehicleBidValueBetween(vMin, vMax)
+
+		if err != nil {
+			return err
+		}
+
+		*fs = append(*fs, f)
+	}
+
+	var from, to time.Time
+
+	if df := c.Query("bidDateFrom"); df != "" {
+		from, err = time.Parse(time.RFC3339, df)
+		if err != nil {
+			return errors.New("bid date from is invalid")
+		}
+	}
+
+	if dt := c.Query("bidDateTo"); dt != "" {
+		to, err = time.Parse(time.RFC3339, dt)
+		if err != nil {
+			return errors.New("bid date to is invalid")
+		}
+	}
+
+	if !from.IsZero() || !to.IsZero() {
+		f, err := filters.NewVehicleBidDateBetween(from, to)
+
+		if err != nil {
+			return err
+		}
+
+		*fs = append(*fs, f)
+	}
+
+	if bd := c.Query("bidder"); bd != "" {
+		*fs = append(*fs, filters.NewVehicleBidder(bd))
+	}
+
+	if hb := c.Query("hasBids"); hb != "" {
+		has, err := strconv.ParseBool(hb)
+		if err != nil {
+			return errors.New("has bids is invalid")
+		}
+
+		*fs = append(*fs, filters.NewVehicleHasBids(has))
+	}
+
+	if q := c.Query("q"); q != "" {
+		f, err := query.Filter(q)
+
+		var syntax *query.SyntaxError
+		if errors.As(err, &syntax) {
+			return handler.BadRequest{Message: "q is invalid: " + syntax.Error(), Position: syntax.Position}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		*fs = append(*fs, f)
+	}
+
 	mfy, err := strconv.ParseInt(c.DefaultQuery("manufacturingYear", "0"), 10, 32)
 	if err != nil {
 		return errors.New("manufacturing year is invalid")
@@ -154,6 +303,25 @@ func (v vehicleCtrl) All(c *gin.Context) {
 	var fs []filters.Filter
 	err := buildFilters(c, &fs)
 
+	if b, ok := err.(handler.BadRequest); ok {
+		handler.ResponseError(b, c)
+		return
+	}
+
+	if err != nil {
+		handler.ResponseError(handler.BadRequest{Message: err.Error()}, c)
+		return
+	}
+
+	query, err := buildPageQuery(c)
+
+	if err != nil {
+		handler.ResponseError(handler.BadRequest{Message: err.Error()}, c)
+		return
+	}
+
+	order, err := buildOrder(c)
+
 	if err != nil {
 		handler.ResponseError(handler.BadRequest{Message: err.Error()}, c)
 		return
@@ -161,15 +329,19 @@ func (v vehicleCtrl) All(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
 
-	items, err := v.srv.All(ctx, fs, c.Query("bidOrder"))
+	page, err := v.srv.List(ctx, fs, order, query)
 
 	if err != nil {
 		handler.ResponseError(err, c)
 		return
 	}
 
-	handler.ResponseSuccess(200, items, c)
+	writeReport(c, report)
+	writeLinks(c, page)
+
+	handler.ResponseSuccess(200, vehiclePageResponse{VehiclePage: page, Warnings: report.Warnings()}, c)
 }
 
 func (v vehicleCtrl) ByID(c *gin.Context) {
@@ -187,6 +359,7 @@ func (v vehicleCtrl) ByID(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
 
 	ve, err := v.srv.ByID(ctx, int(id))
 
@@ -195,6 +368,9 @@ func (v vehicleCtrl) ByID(c *gin.Context) {
 		return
 	}
 
+	writeReport(c, report)
+	writeETag(c, *ve)
+
 	res := response{
 		Vehicle: *ve,
 		Links: []Link{
@@ -227,8 +403,8 @@ func (v vehicleCtrl) Update(c *gin.Context) {
 		return
 	}
 
-	var ve entity.Vehicle
-	err = c.BindJSON(&ve)
+	var req vehicleRequest
+	err = c.BindJSON(&req)
 
 	if err != nil {
 		handler.ResponseError(
@@ -240,18 +416,27 @@ func (v vehicleCtrl) Update(c *gin.Context) {
 		return
 	}
 
+	ve := req.Vehicle
 	ve.ID = int(id)
+	ve.Reserve = req.ReservePrice
+
+	if err := validation.Vehicle(ve); err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	err = v.srv.Update(ctx, &ve)
+	err = v.srv.Update(ctx, &ve, ifMatch(c))
 
 	if err != nil {
 		handler.ResponseError(err, c)
 		return
 	}
 
+	writeETag(c, ve)
+
 	res := response{
 		Vehicle: ve,
 		Links: []Link{
@@ -287,7 +472,7 @@ func (v vehicleCtrl) Delete(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	err = v.srv.Delete(ctx, int(id))
+	err = v.srv.Delete(ctx, int(id), ifMatch(c))
 
 	if err != nil {
 		handler.ResponseError(err, c)
@@ -296,3 +481,255 @@ func (v vehicleCtrl) Delete(c *gin.Context) {
 
 	handler.ResponseSuccess(200, nil, c)
 }
+
+// buildMoney reads an amount in the default currency, nil when it is blank
+func buildMoney(value string) (*entity.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	m, err := entity.ParseMoney(value, entity.DefaultCurrency)
+
+	if err != nil || m.IsNegative() {
+		return nil, errors.New("amount is invalid")
+	}
+
+	return &m, nil
+}
+
+// buildPage reads the page number and size, zero takes the defaults
+func buildPage(c *gin.Context) (paging.Page, error) {
+	number, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil {
+		return paging.Page{}, errors.New("page is invalid")
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("pageSize", "0"))
+	if err != nil {
+		return paging.Page{}, errors.New("page size is invalid")
+	}
+
+	return paging.New(number, size)
+}
+
+// buildOrder reads the sort of a vehicle list, bidOrder orders by the bid date and value after the sort fields
+func buildOrder(c *gin.Context) (string, error) {
+	keys := []string{}
+
+	if sort := c.Query("sort"); sort != "" {
+		keys = append(keys, sort)
+	}
+
+	switch c.Query("bidOrder") {
+	case "":
+	case "asc":
+		keys = append(keys, "bid.date,bid.value")
+	case "desc":
+		keys = append(keys, "-bid.date,-bid.value")
+	default:
+		return "", errors.New("bid order is invalid")
+	}
+
+	return strings.Join(keys, ","), nil
+}
+
+// buildPageQuery reads the page of a vehicle list, by number or by the cursor of the page before
+func buildPageQuery(c *gin.Context) (vehicle.PageQuery, error) {
+	page, err := buildPage(c)
+
+	return vehicle.PageQuery{Page: page, Cursor: c.Query("cursor")}, err
+}
+
+// buildBidQuery reads the page and the RFC 3339 date range of the bid history
+func buildBidQuery(c *gin.Context) (vehicle.BidQuery, error) {
+	var q vehicle.BidQuery
+	var err error
+
+	q.Page, err = buildPage(c)
+	if err != nil {
+		return q, err
+	}
+
+	if from := c.Query("from"); from != "" {
+		q.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return q, errors.New("from is invalid")
+		}
+	}
+
+	if to := c.Query("to"); to != "" {
+		q.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return q, errors.New("to is invalid")
+		}
+	}
+
+	return q, nil
+}
+
+func (v vehicleCtrl) Bids(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+
+	if err != nil {
+		handler.ResponseError(
+			handler.BadRequest{
+				Message: "id is invalid",
+			},
+			c,
+		)
+		return
+	}
+
+	q, err := buildBidQuery(c)
+
+	if err != nil {
+		handler.ResponseError(handler.BadRequest{Message: err.Error()}, c)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
+
+	page, err := v.srv.Bids(ctx, int(id), q)
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	writeReport(c, report)
+
+	handler.ResponseSuccess(200, page, c)
+}
+
+func (v vehicleCtrl) BidsByUser(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx, report := legacy.WithReport(ctx)
+
+	bids, err := v.srv.BidsByUser(ctx, c.Param("user"))
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	writeReport(c, report)
+
+	handler.ResponseSuccess(200, bids, c)
+}
+
+func (v vehicleCtrl) PlaceBid(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+
+	if err != nil {
+		handler.ResponseError(
+			handler.BadRequest{
+				Message: "id is invalid",
+			},
+			c,
+		)
+		return
+	}
+
+	var bid bidRequest
+	err = c.BindJSON(&bid)
+
+	if err != nil {
+		handler.ResponseError(
+			handler.BadRequest{
+				Message: "body is invalid",
+			},
+			c,
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	ve, err := v.srv.PlaceBid(ctx, int(id), bid.User, bid.Value)
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	writeETag(c, *ve)
+
+	res := response{
+		Vehicle: *ve,
+		Links: []Link{
+			{
+				Relation:     "self",
+				RelationType: "GET",
+				URI:          c.Request.RequestURI,
+			},
+			{
+				Relation:     "vehicle",
+				RelationType: "GET",
+				URI:          strings.TrimSuffix(c.Request.RequestURI, "/bids"),
+			},
+		},
+	}
+
+	handler.ResponseSuccess(201, res, c)
+}
+
+func (v vehicleCtrl) SetMaxBid(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+
+	if err != nil {
+		handler.ResponseError(
+			handler.BadRequest{
+				Message: "id is invalid",
+			},
+			c,
+		)
+		return
+	}
+
+	var max maxBidRequest
+	err = c.BindJSON(&max)
+
+	if err != nil {
+		handler.ResponseError(
+			handler.BadRequest{
+				Message: "body is invalid",
+			},
+			c,
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	ve, err := v.srv.SetMaxBid(ctx, int(id), max.User, max.Max)
+
+	if err != nil {
+		handler.ResponseError(err, c)
+		return
+	}
+
+	writeETag(c, *ve)
+
+	res := response{
+		Vehicle: *ve,
+		Links: []Link{
+			{
+				Relation:     "bids",
+				RelationType: "GET",
+				URI:          strings.TrimSuffix(c.Request.RequestURI, "/max-bids") + "/bids",
+			},
+			{
+				Relation:     "vehicle",
+				RelationType: "GET",
+				URI:          strings.TrimSuffix(c.Request.RequestURI, "/max-bids"),
+			},
+		},
+	}
+
+	handler.ResponseSuccess(200, res, c)
+}
@@ -2,25 +2,31 @@ package controller_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"maga-auctions/api/controller"
+	"maga-auctions/api/helper/paging"
+	"maga-auctions/entity"
 	"maga-auctions/legacy"
 	"maga-auctions/vehicle"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreate(t *testing.T) {
-	t.Run("must create a new vehicle", func(t *testing.T) {
+	t.Run("must create a new vehicle without the bid of the body", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		body := bytes.NewBufferString(`{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`)
+		body := bytes.NewBufferString(`{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`)
 		c.Request, _ = http.NewRequest("POST", "/vehicles", body)
 
-		mockApiLegacy("testdata/criar_response_api.json", 200)
+		mockApiLegacyWrite("testdata/criar_response_api.json", 200)
 
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
@@ -31,7 +37,25 @@ func TestCreate(t *testing.T) {
 		assert.Equal(t, w.HeaderMap["Location"][0], "/9999")
 		assert.JSONEq(
 			t,
-			`{"vehicle":{"id":9999,"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}},"links":[{"uri":"/9999","rel":"self","type":"GET"},{"uri":"/9999","rel":"self","type":"PUT"},{"uri":"/9999","rel":"self","type":"DELETE"}]}`,
+			`{"vehicle":{"id":9999,"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"0001-01-01T00:00:00Z","value":0,"user":""},"reserveMet":true},"links":[{"uri":"/9999","rel":"self","type":"GET"},{"uri":"/9999","rel":"self","type":"PUT"},{"uri":"/9999","rel":"self","type":"DELETE"}]}`,
+			w.Body.String(),
+		)
+	})
+
+	t.Run("must keep the reserve price out of the response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		body := bytes.NewBufferString(`{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"},"reservePrice":20000}`)
+		c.Request, _ = http.NewRequest("POST", "/vehicles", body)
+
+		mockApiLegacyWrite("testdata/criar_response_api.json", 200)
+
+		controller.NewVehicle(vehicle.NewService(legacy.NewAPI())).Create(c)
+
+		assert.Equal(t, 201, w.Code)
+		assert.JSONEq(
+			t,
+			`{"vehicle":{"id":9999,"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"0001-01-01T00:00:00Z","value":0,"user":""},"reserveMet":false},"links":[{"uri":"/9999","rel":"self","type":"GET"},{"uri":"/9999","rel":"self","type":"PUT"},{"uri":"/9999","rel":"self","type":"DELETE"}]}`,
 			w.Body.String(),
 		)
 	})
@@ -49,11 +73,24 @@ func TestCreate_Errors(t *testing.T) {
 			wantJson:   `{"error":"body is invalid"}`,
 		},
 		{
-			desc:       "must return error when legacy api fails",
+			desc:       "must return error when the vehicle breaks the rules",
+			body:       `{"brand":"","model":"CLIO 16VS","modelYear":2006,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":-15000,"user":"ALLBARBOS"}}`,
+			wantStatus: 422,
+			wantJson:   `{"error":"vehicle is invalid","fields":[{"field":"brand","rule":"required","message":"brand is required"},{"field":"modelYear","rule":"gtefield","message":"modelYear cannot be before manufacturingYear"}]}`,
+		},
+		{
+			desc:       "must return error when the control code is taken in the lot",
 			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`,
+			jsonPATH:   "testdata/criar_response_api.json",
+			wantStatus: 409,
+			wantJson:   `{"error":"vehicle lot id 56248 is already in lot 0196"}`,
+		},
+		{
+			desc:       "must return error when legacy api fails",
+			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`,
 			jsonPATH:   "testdata/criar_response_error_api.json",
-			wantStatus: 500,
-			wantJson:   `{"error":"internal server error"}`,
+			wantStatus: 502,
+			wantJson:   `{"error":"error when creating the vehicle in legacy api"}`,
 		},
 	}
 
@@ -63,7 +100,7 @@ func TestCreate_Errors(t *testing.T) {
 			c, _ := gin.CreateTestContext(w)
 			body := bytes.NewBufferString(tt.body)
 			c.Request, _ = http.NewRequest("POST", "/vehicles", body)
-			mockApiLegacy(tt.jsonPATH, 200)
+			mockApiLegacyWrite(tt.jsonPATH, 200)
 
 			api := legacy.NewAPI()
 			srv := vehicle.NewService(api)
@@ -80,6 +117,51 @@ func TestCreate_Errors(t *testing.T) {
 	}
 }
 
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		desc, body, wantJson string
+		wantStatus           int
+	}{
+		{
+			desc:       "must accept a valid vehicle",
+			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`,
+			wantStatus: 200,
+			wantJson:   `{"valid":true}`,
+		},
+		{
+			desc:       "must return error when body is invalid",
+			body:       "{",
+			wantStatus: 400,
+			wantJson:   `{"error":"body is invalid"}`,
+		},
+		{
+			desc:       "must return error when the vehicle breaks the rules",
+			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196"},"bid":{"value":15000}}`,
+			wantStatus: 422,
+			wantJson:   `{"error":"vehicle is invalid","fields":[{"field":"lot.vehicleLotId","rule":"required","message":"lot.vehicleLotId is required"}]}`,
+		},
+		{
+			desc:       "must return error when the reserve price is negative",
+			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"reservePrice":-0.01}`,
+			wantStatus: 422,
+			wantJson:   `{"error":"vehicle is invalid","fields":[{"field":"reservePrice","rule":"gte","message":"reservePrice must be at least 0"}]}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/vehicles/validate", bytes.NewBufferString(tt.body))
+
+			controller.NewVehicle(vehicle.NewService(legacy.NewAPI())).Validate(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
 func TestAll(t *testing.T) {
 	t.Run("must return a list of vehicle", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -95,12 +177,143 @@ func TestAll(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 		assert.JSONEq(
 			t,
-			`[{"id":544,"brand":"RENAULT","model":"SYMBOL EX1616V","modelYear":2011,"manufacturingYear":2011,"lot":{"id":"0046","vehicleLotId":"716797"},"bid":{"date":"2020-08-22T09:48:00Z","value":4500,"user":"Sorico1"}}]`,
+			`{"items":[{"id":544,"brand":"RENAULT","model":"SYMBOL EX1616V","modelYear":2011,"manufacturingYear":2011,"lot":{"id":"0046","vehicleLotId":"716797"},"bid":{"date":"2020-08-22T09:48:00Z","value":4500,"user":"Sorico1"},"reserveMet":true}],"total":1,"page":1,"pageSize":20}`,
 			w.Body.String(),
 		)
 	})
 }
 
+func TestAll_DefaultPage(t *testing.T) {
+	t.Run("must page the list without the paging params", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/vehicles", nil)
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		controller.NewVehicle(vehicle.NewService(legacy.NewAPI())).All(c)
+
+		var body struct {
+			Items    []json.RawMessage `json:"items"`
+			Total    int               `json:"total"`
+			PageSize int               `json:"pageSize"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, paging.DefaultSize, body.PageSize)
+		assert.Len(t, body.Items, paging.DefaultSize)
+		assert.Greater(t, body.Total, paging.DefaultSize)
+	})
+}
+
+func TestAll_Query(t *testing.T) {
+	t.Run("must filter by the expression", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/vehicles?brand=renault&q="+url.QueryEscape("model:SYMBOL* AND NOT (manufacturingYear<2011 OR bid.user:sorico2)"), nil)
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		controller.NewVehicle(vehicle.NewService(legacy.NewAPI())).All(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(
+			t,
+			`{"items":[{"id":544,"brand":"RENAULT","model":"SYMBOL EX1616V","modelYear":2011,"manufacturingYear":2011,"lot":{"id":"0046","vehicleLotId":"716797"},"bid":{"date":"2020-08-22T09:48:00Z","value":4500,"user":"Sorico1"},"reserveMet":true}],"total":1,"page":1,"pageSize":20}`,
+			w.Body.String(),
+		)
+	})
+}
+
+func TestAll_BidFilters(t *testing.T) {
+	testCases := []struct {
+		desc, query string
+		want        []int
+	}{
+		{
+			desc:  "must filter by bid value and date",
+			query: "/vehicles?brand=renault&model=S&bidValueMin=4500&bidValueMax=4500.00&bidDateFrom=2020-08-22T09:48:00Z&bidDateTo=2020-08-22T09:48:00Z",
+			want:  []int{544},
+		},
+		{
+			desc:  "must filter by bidder",
+			query: "/vehicles?bidder=luscas&q=lot.id:9999",
+			want:  []int{460, 486, 509},
+		},
+		{
+			desc:  "must filter the vehicles with bids",
+			query: "/vehicles?q=lot.id:0161&hasBids=true",
+			want:  []int{180, 725},
+		},
+		{
+			desc:  "must filter the vehicles without bids",
+			query: "/vehicles?q=lot.id:0161&hasBids=false",
+			want:  []int{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", tt.query, nil)
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			controller.NewVehicle(vehicle.NewService(legacy.NewAPI())).All(c)
+
+			var body struct {
+				Items []struct {
+					ID int `json:"id"`
+				} `json:"items"`
+			}
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+			ids := []int{}
+			for _, item := range body.Items {
+				ids = append(ids, item.ID)
+			}
+
+			assert.Equal(t, 200, w.Code)
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestAll_Warnings(t *testing.T) {
+	testCases := []struct {
+		desc, policy, wantJson string
+	}{
+		{
+			desc:     "must warn about the skipped rows",
+			policy:   legacy.SkipInvalidDate,
+			wantJson: `{"items":[],"total":0,"page":1,"pageSize":20,"warnings":[{"id":305,"field":"DATALANCE","value":"22/08/2020","action":"skipped"}]}`,
+		},
+		{
+			desc:     "must warn about the rows with a zero bid date",
+			policy:   legacy.ZeroInvalidDate,
+			wantJson: `{"items":[{"id":305,"brand":"RENAULT","model":"CLIO EXP1016VH","modelYear":2016,"manufacturingYear":2015,"lot":{"id":"0361","vehicleLotId":"731906"},"bid":{"date":"0001-01-01T00:00:00Z","value":2500,"user":"Jarraoilha"},"reserveMet":true}],"total":1,"page":1,"pageSize":20,"warnings":[{"id":305,"field":"DATALANCE","value":"22/08/2020","action":"zeroed"}]}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/vehicles?model=CLIO%20EXP1", nil)
+			mockApiLegacy("../../legacy/testdata/consultar_response_api.json", 200)
+			legacy.InvalidBidDate = tt.policy
+			defer func() { legacy.InvalidBidDate = legacy.SkipInvalidDate }()
+
+			api := legacy.NewAPI()
+			srv := vehicle.NewService(api)
+
+			controller.NewVehicle(srv).All(c)
+
+			assert.Equal(t, 200, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
 func TestAll_Errors(t *testing.T) {
 	testCases := []struct {
 		desc, jsonPATH, query, wantJson string
@@ -142,11 +355,109 @@ func TestAll_Errors(t *testing.T) {
 			wantStatus: 400,
 			wantJson:   `{"error":"year of manufacture cannot be greater than the model"}`,
 		},
+		{
+			desc:       "must return error when reserve met is invalid",
+			query:      "/vehicles?reserveMet=maybe",
+			wantStatus: 400,
+			wantJson:   `{"error":"reserve met is invalid"}`,
+		},
+		{
+			desc:       "must return error when bid value min is invalid",
+			query:      "/vehicles?bidValueMin=a",
+			wantStatus: 400,
+			wantJson:   `{"error":"bid value min is invalid"}`,
+		},
+		{
+			desc:       "must return error when bid value max is negative",
+			query:      "/vehicles?bidValueMax=-1",
+			wantStatus: 400,
+			wantJson:   `{"error":"bid value max is invalid"}`,
+		},
+		{
+			desc:       "must return error when bid value max is less than min",
+			query:      "/vehicles?bidValueMin=100&bidValueMax=99.99",
+			wantStatus: 400,
+			wantJson:   `{"error":"bid value max cannot be less than min"}`,
+		},
+		{
+			desc:       "must return error when bid date from is invalid",
+			query:      "/vehicles?bidDateFrom=2020-08-21",
+			wantStatus: 400,
+			wantJson:   `{"error":"bid date from is invalid"}`,
+		},
+		{
+			desc:       "must return error when bid date to is invalid",
+			query:      "/vehicles?bidDateTo=yesterday",
+			wantStatus: 400,
+			wantJson:   `{"error":"bid date to is invalid"}`,
+		},
+		{
+			desc:       "must return error when bid date to is before from",
+			query:      "/vehicles?bidDateFrom=2020-08-22T00:00:00Z&bidDateTo=2020-08-21T00:00:00Z",
+			wantStatus: 400,
+			wantJson:   `{"error":"bid date to cannot be before from"}`,
+		},
+		{
+			desc:       "must return error when has bids is invalid",
+			query:      "/vehicles?hasBids=maybe",
+			wantStatus: 400,
+			wantJson:   `{"error":"has bids is invalid"}`,
+		},
+		{
+			desc:       "must return error with the position when q is invalid",
+			query:      "/vehicles?q=brand:FIAT%20OR%20color:RED",
+			wantStatus: 400,
+			wantJson:   `{"error":"q is invalid: unknown field \"color\" at position 15","position":15}`,
+		},
+		{
+			desc:       "must return error with the position when q cannot be parsed",
+			query:      "/vehicles?q=(brand:FIAT%20OR%20model:UNO",
+			wantStatus: 400,
+			wantJson:   `{"error":"q is invalid: expected \")\", found end of expression at position 25","position":25}`,
+		},
+		{
+			desc:       "must return error when bid order is invalid",
+			query:      "/vehicles?bidOrder=up",
+			wantStatus: 400,
+			wantJson:   `{"error":"bid order is invalid"}`,
+		},
+		{
+			desc:       "must return error when a sort field is unknown",
+			query:      "/vehicles?sort=-bid.value,color",
+			wantStatus: 400,
+			wantJson:   `{"error":"vehicles cannot be sorted by \"color\""}`,
+		},
+		{
+			desc:       "must return error when page size is invalid",
+			query:      "/vehicles?pageSize=a",
+			wantStatus: 400,
+			wantJson:   `{"error":"page size is invalid"}`,
+		},
+		{
+			desc:       "must return error when page size is above the max",
+			query:      "/vehicles?pageSize=1000",
+			wantStatus: 400,
+			wantJson:   `{"error":"page size is invalid"}`,
+		},
+		{
+			desc:       "must return error when cursor is invalid",
+			jsonPATH:   "testdata/consultar_response_api.json",
+			query:      "/vehicles?cursor=abc",
+			wantStatus: 400,
+			wantJson:   `{"error":"cursor is invalid"}`,
+		},
+		{
+			desc:       "must return error when page and cursor are used together",
+			jsonPATH:   "testdata/consultar_response_api.json",
+			query:      "/vehicles?page=2&cursor=eyJvIjoxLCJrIjoiMTgwIn0",
+			wantStatus: 400,
+			wantJson:   `{"error":"page and cursor cannot be used together"}`,
+		},
 		{
 			desc:       "must return error when legacy api fails",
 			jsonPATH:   "testdata/consultar_response_error_api.json",
-			wantStatus: 500,
-			wantJson:   `{"error":"internal server error"}`,
+			wantStatus: 502,
+			wantJson:   `{"error":"error when searching for vehicles in legacy api"}`,
 		},
 	}
 
@@ -184,7 +495,7 @@ func TestByID(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 		assert.JSONEq(
 			t,
-			`{"vehicle":{"id":760,"brand":"IVECO","model":"EUROCARGO 260E25N","modelYear":2012,"manufacturingYear":2011,"lot":{"id":"0068","vehicleLotId":"126845"},"bid":{"date":"2020-08-27T10:20:00Z","value":75000,"user":"ALDOBARROSO"}},"links":[{"uri":"","rel":"self","type":"PUT"},{"uri":"","rel":"self","type":"DELETE"}]}`,
+			`{"vehicle":{"id":760,"brand":"IVECO","model":"EUROCARGO 260E25N","modelYear":2012,"manufacturingYear":2011,"lot":{"id":"0068","vehicleLotId":"126845"},"bid":{"date":"2020-08-27T10:20:00Z","value":75000,"user":"ALDOBARROSO"},"reserveMet":true},"links":[{"uri":"","rel":"self","type":"PUT"},{"uri":"","rel":"self","type":"DELETE"}]}`,
 			w.Body.String(),
 		)
 	})
@@ -205,8 +516,15 @@ func TestByID_Errors(t *testing.T) {
 			desc:       "must return error when legacy api fails",
 			id:         "760",
 			jsonPATH:   "testdata/consultar_response_error_api.json",
-			wantStatus: 500,
-			wantJson:   `{"error":"internal server error"}`,
+			wantStatus: 502,
+			wantJson:   `{"error":"error when searching for vehicles in legacy api"}`,
+		},
+		{
+			desc:       "must return error when vehicle is not found",
+			id:         "9999",
+			jsonPATH:   "testdata/consultar_response_api.json",
+			wantStatus: 404,
+			wantJson:   `{"error":"vehicle not found"}`,
 		},
 	}
 
@@ -230,14 +548,14 @@ func TestByID_Errors(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
-	t.Run("must update a new vehicle", func(t *testing.T) {
+	t.Run("must update a vehicle and keep its stored bid", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = []gin.Param{{Key: "id", Value: "760"}}
-		body := bytes.NewBufferString(`{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`)
+		body := bytes.NewBufferString(`{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`)
 		c.Request, _ = http.NewRequest("PUT", "/vehicles", body)
 
-		mockApiLegacy("testdata/alterar_response_api.json", 200)
+		mockApiLegacyWrite("testdata/alterar_response_api.json", 200)
 
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
@@ -247,7 +565,7 @@ func TestUpdate(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 		assert.JSONEq(
 			t,
-			`{"vehicle":{"id":760,"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}},"links":[{"uri":"","rel":"self","type":"GET"},{"uri":"","rel":"self","type":"DELETE"}]}`,
+			`{"vehicle":{"id":760,"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":75000,"user":"ALDOBARROSO"},"reserveMet":true},"links":[{"uri":"","rel":"self","type":"GET"},{"uri":"","rel":"self","type":"DELETE"}]}`,
 			w.Body.String(),
 		)
 	})
@@ -272,11 +590,11 @@ func TestUpdate_Errors(t *testing.T) {
 			wantJson:   `{"error":"body is invalid"}`,
 		},
 		{
-			desc:       "must return error when legacy api fails",
+			desc:       "must return error when vehicle is not found in legacy api",
 			id:         "760",
-			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`,
-			wantStatus: 400,
-			wantJson:   `{"error":"id not found"}`,
+			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`,
+			wantStatus: 404,
+			wantJson:   `{"error":"vehicle not found"}`,
 		},
 	}
 
@@ -287,7 +605,7 @@ func TestUpdate_Errors(t *testing.T) {
 			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
 			body := bytes.NewBufferString(tt.body)
 			c.Request, _ = http.NewRequest("PUT", "/vehicles", body)
-			mockApiLegacy("testdata/alterar_response_error_api.json", 200)
+			mockApiLegacyWrite("testdata/alterar_response_error_api.json", 200)
 
 			api := legacy.NewAPI()
 			srv := vehicle.NewService(api)
@@ -305,8 +623,9 @@ func TestDelete(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = []gin.Param{{Key: "id", Value: "760"}}
+		c.Request, _ = http.NewRequest("DELETE", "/vehicles/760", nil)
 
-		mockApiLegacy("testdata/apagar_response_api.json", 200)
+		mockApiLegacyWrite("testdata/apagar_response_api.json", 200)
 
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
@@ -329,11 +648,11 @@ func TestDelete_Errors(t *testing.T) {
 			wantJson:   `{"error":"id is invalid"}`,
 		},
 		{
-			desc:       "must return error when legacy api fails",
+			desc:       "must return error when vehicle is not found in legacy api",
 			id:         "760",
-			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56248"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`,
-			wantStatus: 400,
-			wantJson:   `{"error":"id not found"}`,
+			body:       `{"brand":"RENAULT","model":"CLIO 16VS","modelYear":2007,"manufacturingYear":2007,"lot":{"id":"0196","vehicleLotId":"56249"},"bid":{"date":"2020-08-27T10:20:00Z","value":15000,"user":"ALLBARBOS"}}`,
+			wantStatus: 404,
+			wantJson:   `{"error":"vehicle not found"}`,
 		},
 	}
 
@@ -342,7 +661,8 @@ func TestDelete_Errors(t *testing.T) {
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
 			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
-			mockApiLegacy("testdata/apagar_response_error_api.json", 200)
+			c.Request, _ = http.NewRequest("DELETE", "/vehicles/"+tt.id, nil)
+			mockApiLegacyWrite("testdata/apagar_response_error_api.json", 200)
 
 			api := legacy.NewAPI()
 			srv := vehicle.NewService(api)
@@ -354,3 +674,366 @@ func TestDelete_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestIfMatch(t *testing.T) {
+	read := func(t *testing.T, srv vehicle.Service) string {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "760"}}
+		c.Request, _ = http.NewRequest("GET", "/vehicles/760", nil)
+
+		controller.NewVehicle(srv).ByID(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+
+		return w.Header().Get("ETag")
+	}
+
+	testCases := []struct {
+		desc, method, body, etag string
+		wantStatus               int
+	}{
+		{
+			desc:       "must update when the version matches",
+			method:     "PUT",
+			body:       `{"brand":"IVECO","model":"EUROCARGO 260E25N","modelYear":2012,"manufacturingYear":2011,"lot":{"id":"0068","vehicleLotId":"126845"},"bid":{"date":"2020-08-27T10:20:00Z","value":80000,"user":"ALLBARBOS"}}`,
+			wantStatus: 200,
+		},
+		{
+			desc:       "must reject a stale update",
+			method:     "PUT",
+			body:       `{"brand":"IVECO","model":"EUROCARGO 260E25N","modelYear":2012,"manufacturingYear":2011,"lot":{"id":"0068","vehicleLotId":"126845"},"bid":{"date":"2020-08-27T10:20:00Z","value":80000,"user":"ALLBARBOS"}}`,
+			etag:       `"0000000000000000"`,
+			wantStatus: 412,
+		},
+		{
+			desc:       "must delete when any version is accepted",
+			method:     "DELETE",
+			etag:       "*",
+			wantStatus: 200,
+		},
+		{
+			desc:       "must reject a stale delete",
+			method:     "DELETE",
+			etag:       `W/"0000000000000000"`,
+			wantStatus: 412,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			srv := vehicle.NewService(legacy.NewAPI())
+			etag := tt.etag
+
+			if etag == "" {
+				etag = read(t, srv)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: "760"}}
+			c.Request, _ = http.NewRequest(tt.method, "/vehicles/760", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("If-Match", etag)
+
+			if tt.method == "PUT" {
+				controller.NewVehicle(srv).Update(c)
+			} else {
+				controller.NewVehicle(srv).Delete(c)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == 412 {
+				assert.JSONEq(t, `{"error":"vehicle was changed, read it again before writing"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAll_Stale(t *testing.T) {
+	t.Run("must flag the response as stale while the circuit is open", func(t *testing.T) {
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		breaker := legacy.NewBreaker(legacy.NewAPI(), legacy.BreakerPolicy{FailureThreshold: 1, CoolDown: time.Minute})
+		srv := vehicle.NewService(breaker)
+		_, _ = breaker.Get(context.Background())
+
+		mockApiLegacy("", 503)
+		_, _ = breaker.Get(context.Background())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/vehicles?brand=iveco", nil)
+
+		controller.NewVehicle(srv).All(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, `110 - "Response is Stale"`, w.Header().Get("Warning"))
+		assert.Equal(t, "0", w.Header().Get("Age"))
+	})
+}
+
+func TestBids(t *testing.T) {
+	t.Run("must return the bid history", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "760"}}
+		c.Request, _ = http.NewRequest("GET", "/vehicles/760/bids?from=2020-08-27T00:00:00Z&page=1&pageSize=10", nil)
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		api := legacy.NewAPI()
+		srv := vehicle.NewService(api)
+
+		controller.NewVehicle(srv).Bids(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(
+			t,
+			`{"items":[{"date":"2020-08-27T10:20:00Z","value":75000,"user":"ALDOBARROSO"}],"page":1,"pageSize":10,"total":1}`,
+			w.Body.String(),
+		)
+	})
+}
+
+func TestBids_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, id, query, wantJson string
+		wantStatus                int
+	}{
+		{
+			desc:       "must return error when id is invalid",
+			id:         "a",
+			wantStatus: 400,
+			wantJson:   `{"error":"id is invalid"}`,
+		},
+		{
+			desc:       "must return error when from is invalid",
+			id:         "760",
+			query:      "?from=27/08/2020",
+			wantStatus: 400,
+			wantJson:   `{"error":"from is invalid"}`,
+		},
+		{
+			desc:       "must return error when page is invalid",
+			id:         "760",
+			query:      "?page=a",
+			wantStatus: 400,
+			wantJson:   `{"error":"page is invalid"}`,
+		},
+		{
+			desc:       "must return error when vehicle is not found",
+			id:         "9999",
+			wantStatus: 404,
+			wantJson:   `{"error":"vehicle not found"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+			c.Request, _ = http.NewRequest("GET", "/vehicles/"+tt.id+"/bids"+tt.query, nil)
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			api := legacy.NewAPI()
+			srv := vehicle.NewService(api)
+
+			controller.NewVehicle(srv).Bids(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
+func TestPlaceBid(t *testing.T) {
+	t.Run("must place the bid", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "760"}}
+		body := bytes.NewBufferString(`{"user":"ALLBARBOS","value":"75100.50","date":"2000-01-01T00:00:00Z"}`)
+		c.Request, _ = http.NewRequest("POST", "/vehicles/760/bids", body)
+		c.Request.RequestURI = "/vehicles/760/bids"
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		now := func() time.Time { return time.Date(2020, 8, 28, 9, 30, 42, 0, time.UTC) }
+		srv := vehicle.NewService(legacy.NewAPI(), vehicle.WithClock(now))
+
+		controller.NewVehicle(srv).PlaceBid(c)
+
+		assert.Equal(t, 201, w.Code)
+		assert.JSONEq(
+			t,
+			`{"vehicle":{"id":760,"brand":"IVECO","model":"EUROCARGO 260E25N","modelYear":2012,"manufacturingYear":2011,"lot":{"id":"0068","vehicleLotId":"126845"},"bid":{"date":"2020-08-28T09:30:00Z","value":75100.50,"user":"ALLBARBOS"},"reserveMet":true},"links":[{"uri":"/vehicles/760/bids","rel":"self","type":"GET"},{"uri":"/vehicles/760","rel":"vehicle","type":"GET"}]}`,
+			w.Body.String(),
+		)
+	})
+}
+
+func TestPlaceBid_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, id, body, wantJson string
+		wantStatus               int
+	}{
+		{
+			desc:       "must return error when id is invalid",
+			id:         "a",
+			wantStatus: 400,
+			wantJson:   `{"error":"id is invalid"}`,
+		},
+		{
+			desc:       "must return error when body is invalid",
+			id:         "760",
+			body:       `{"user":"ALLBARBOS","value":"75100.501"}`,
+			wantStatus: 400,
+			wantJson:   `{"error":"body is invalid"}`,
+		},
+		{
+			desc:       "must return error when bid is too low",
+			id:         "760",
+			body:       `{"user":"ALLBARBOS","value":75000}`,
+			wantStatus: 422,
+			wantJson:   `{"error":"bid must be greater than 75000.00 by at least 0.00"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+			c.Request, _ = http.NewRequest("POST", "/vehicles/"+tt.id+"/bids", bytes.NewBufferString(tt.body))
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			srv := vehicle.NewService(legacy.NewAPI())
+
+			controller.NewVehicle(srv).PlaceBid(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
+func TestSetMaxBid(t *testing.T) {
+	t.Run("must bid for the user up to the max", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "id", Value: "760"}}
+		c.Request, _ = http.NewRequest("POST", "/vehicles/760/max-bids", bytes.NewBufferString(`{"user":"ALLBARBOS","max":80000}`))
+		c.Request.RequestURI = "/vehicles/760/max-bids"
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		now := func() time.Time { return time.Date(2020, 8, 28, 9, 30, 42, 0, time.UTC) }
+		srv := vehicle.NewService(legacy.NewAPI(), vehicle.WithClock(now), vehicle.WithMinIncrement(entity.NewMoney(10000, "")))
+
+		controller.NewVehicle(srv).SetMaxBid(c)
+
+		assert.Equal(t, 200, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.JSONEq(
+			t,
+			`{"vehicle":{"id":760,"brand":"IVECO","model":"EUROCARGO 260E25N","modelYear":2012,"manufacturingYear":2011,"lot":{"id":"0068","vehicleLotId":"126845"},"bid":{"date":"2020-08-28T09:30:00Z","value":75100,"user":"ALLBARBOS"},"reserveMet":true},"links":[{"uri":"/vehicles/760/bids","rel":"bids","type":"GET"},{"uri":"/vehicles/760","rel":"vehicle","type":"GET"}]}`,
+			w.Body.String(),
+		)
+	})
+}
+
+func TestSetMaxBid_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, id, body, wantJson string
+		wantStatus               int
+	}{
+		{
+			desc:       "must return error when id is invalid",
+			id:         "a",
+			wantStatus: 400,
+			wantJson:   `{"error":"id is invalid"}`,
+		},
+		{
+			desc:       "must return error when body is invalid",
+			id:         "760",
+			body:       `{"user":"ALLBARBOS","max":"a"}`,
+			wantStatus: 400,
+			wantJson:   `{"error":"body is invalid"}`,
+		},
+		{
+			desc:       "must return error when max is too low",
+			id:         "760",
+			body:       `{"user":"ALLBARBOS","max":70000}`,
+			wantStatus: 422,
+			wantJson:   `{"error":"max bid must be at least 75000.00"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+			c.Request, _ = http.NewRequest("POST", "/vehicles/"+tt.id+"/max-bids", bytes.NewBufferString(tt.body))
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			srv := vehicle.NewService(legacy.NewAPI())
+
+			controller.NewVehicle(srv).SetMaxBid(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
+
+func TestBidsByUser(t *testing.T) {
+	testCases := []struct {
+		desc, user, jsonPATH, wantJson string
+		wantStatus                     int
+	}{
+		{
+			desc:       "must return the top bids of the user by lot",
+			user:       "Michaelnf",
+			jsonPATH:   "testdata/consultar_response_api.json",
+			wantStatus: 200,
+			wantJson:   `{"user":"Michaelnf","vehicles":2,"total":12000,"lots":[{"lotId":"0123","total":6500,"vehicles":[{"id":178,"brand":"MERCEDES-BENZ GLC 250 4MATIC CO 16/17","model":"","modelYear":0,"manufacturingYear":0,"lot":{"id":"0123","vehicleLotId":"723553"},"bid":{"date":"2020-08-21T12:30:00Z","value":6500,"user":"Michaelnf"},"reserveMet":true}]},{"lotId":"0161","total":5500,"vehicles":[{"id":180,"brand":"HONDA","model":"CIVIC SEDAN LXR","modelYear":2015,"manufacturingYear":2014,"lot":{"id":"0161","vehicleLotId":"733135"},"bid":{"date":"2020-08-21T12:58:00Z","value":5500,"user":"Michaelnf"},"reserveMet":true}]}]}`,
+		},
+		{
+			desc:       "must return no lots when the user holds no top bid",
+			user:       "nobody",
+			jsonPATH:   "testdata/consultar_response_api.json",
+			wantStatus: 200,
+			wantJson:   `{"user":"nobody","vehicles":0,"total":0,"lots":[]}`,
+		},
+		{
+			desc:       "must return error when user is blank",
+			user:       " ",
+			wantStatus: 400,
+			wantJson:   `{"error":"invalid user"}`,
+		},
+		{
+			desc:       "must return error when legacy api fails",
+			user:       "Michaelnf",
+			jsonPATH:   "testdata/consultar_response_error_api.json",
+			wantStatus: 502,
+			wantJson:   `{"error":"error when searching for vehicles in legacy api"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = []gin.Param{{Key: "user", Value: tt.user}}
+			c.Request, _ = http.NewRequest("GET", "/users/"+tt.user+"/bids", nil)
+			mockApiLegacy(tt.jsonPATH, 200)
+
+			controller.NewVehicle(vehicle.NewService(legacy.NewAPI())).BidsByUser(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantJson, w.Body.String())
+		})
+	}
+}
@@ -2,9 +2,10 @@ package handler
 
 import "log"
 
-// BadRequest HTTP 400
+// BadRequest HTTP 400, Position points at the 1-based letter of the query the error is about, zero when none
 type BadRequest struct {
-	Message string
+	Message  string
+	Position int
 }
 
 func (b BadRequest) Error() string {
@@ -31,3 +32,65 @@ type NotFound struct {
 func (n NotFound) Error() string {
 	return n.Message
 }
+
+// BadGateway HTTP 502
+type BadGateway struct {
+	Message string
+}
+
+func (b BadGateway) Error() string {
+	return b.Message
+}
+
+// GatewayTimeout HTTP 504
+type GatewayTimeout struct {
+	Message string
+}
+
+func (g GatewayTimeout) Error() string {
+	return g.Message
+}
+
+// ServiceUnavailable HTTP 503
+type ServiceUnavailable struct {
+	Message string
+}
+
+func (s ServiceUnavailable) Error() string {
+	return s.Message
+}
+
+// FieldError is a rule broken by a field of the body
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// UnprocessableEntity HTTP 422
+type UnprocessableEntity struct {
+	Message string
+	Fields  []FieldError
+}
+
+func (u UnprocessableEntity) Error() string {
+	return u.Message
+}
+
+// PreconditionFailed HTTP 412
+type PreconditionFailed struct {
+	Message string
+}
+
+func (p PreconditionFailed) Error() string {
+	return p.Message
+}
+
+// Conflict HTTP 409
+type Conflict struct {
+	Message string
+}
+
+func (c Conflict) Error() string {
+	return c.Message
+}
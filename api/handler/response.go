@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"maga-auctions/legacy"
 	"net/http"
 	"reflect"
 
@@ -28,9 +30,52 @@ func ResponseError(err error, c *gin.Context) {
 		status = http.StatusBadRequest
 	case "handler.NotFound":
 		status = http.StatusNotFound
+	case "handler.BadGateway":
+		status = http.StatusBadGateway
+	case "handler.GatewayTimeout":
+		status = http.StatusGatewayTimeout
+	case "handler.ServiceUnavailable":
+		status = http.StatusServiceUnavailable
+	case "handler.UnprocessableEntity":
+		status = http.StatusUnprocessableEntity
+	case "handler.PreconditionFailed":
+		status = http.StatusPreconditionFailed
+	case "handler.Conflict":
+		status = http.StatusConflict
 	default:
-		status = http.StatusInternalServerError
+		status = legacyStatus(err)
 	}
 
-	c.JSON(status, gin.H{"error": message})
+	body := gin.H{"error": message}
+
+	if u, ok := err.(UnprocessableEntity); ok && len(u.Fields) > 0 {
+		body["fields"] = u.Fields
+	}
+
+	if b, ok := err.(BadRequest); ok && b.Position > 0 {
+		body["position"] = b.Position
+	}
+
+	c.JSON(status, body)
+}
+
+// legacyStatus maps the errors of the legacy api that reach the response unwrapped
+func legacyStatus(err error) int {
+	var upstream legacy.ErrUpstreamStatus
+	var decode legacy.ErrDecode
+
+	switch {
+	case errors.Is(err, legacy.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, legacy.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, legacy.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, legacy.ErrCurrency):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, &upstream), errors.As(err, &decode):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
 }
@@ -1,7 +1,10 @@
 package handler_test
 
 import (
+	"errors"
+	"fmt"
 	"maga-auctions/api/handler"
+	"maga-auctions/legacy"
 	"net/http/httptest"
 	"testing"
 
@@ -38,6 +41,16 @@ func TestResponseError_BadRequest(t *testing.T) {
 	assert.Equal(t, "{\"error\":\"bad request\"}", w.Body.String())
 }
 
+func TestResponseError_BadRequestPosition(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handler.ResponseError(handler.BadRequest{Message: "q is invalid", Position: 7}, c)
+
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, "{\"error\":\"q is invalid\",\"position\":7}", w.Body.String())
+}
+
 func TestResponseError_InternalServer(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -57,3 +70,73 @@ func TestResponseError_NotFound(t *testing.T) {
 	assert.Equal(t, 404, w.Code)
 	assert.Equal(t, "{\"error\":\"not found error\"}", w.Body.String())
 }
+
+func TestResponseError_UnprocessableEntity(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handler.ResponseError(handler.UnprocessableEntity{Message: "bid is too low"}, c)
+
+	assert.Equal(t, 422, w.Code)
+	assert.Equal(t, "{\"error\":\"bid is too low\"}", w.Body.String())
+}
+
+func TestResponseError_UnprocessableEntityFields(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handler.ResponseError(handler.UnprocessableEntity{
+		Message: "vehicle is invalid",
+		Fields:  []handler.FieldError{{Field: "brand", Rule: "required", Message: "brand is required"}},
+	}, c)
+
+	assert.Equal(t, 422, w.Code)
+	assert.JSONEq(t, `{"error":"vehicle is invalid","fields":[{"field":"brand","rule":"required","message":"brand is required"}]}`, w.Body.String())
+}
+
+func TestResponseError_PreconditionFailed(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handler.ResponseError(handler.PreconditionFailed{Message: "vehicle was changed"}, c)
+
+	assert.Equal(t, 412, w.Code)
+	assert.Equal(t, "{\"error\":\"vehicle was changed\"}", w.Body.String())
+}
+
+func TestResponseError_Conflict(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handler.ResponseError(handler.Conflict{Message: "lot 0196 is closed"}, c)
+
+	assert.Equal(t, 409, w.Code)
+	assert.Equal(t, "{\"error\":\"lot 0196 is closed\"}", w.Body.String())
+}
+
+func TestResponseError_Gateway(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		err        error
+		wantStatus int
+	}{
+		{desc: "must return bad gateway", err: handler.BadGateway{Message: "bad gateway"}, wantStatus: 502},
+		{desc: "must return gateway timeout", err: handler.GatewayTimeout{Message: "timeout"}, wantStatus: 504},
+		{desc: "must map legacy not found", err: legacy.ErrNotFound, wantStatus: 404},
+		{desc: "must map legacy timeout", err: fmt.Errorf("%w: slow", legacy.ErrTimeout), wantStatus: 504},
+		{desc: "must map legacy status", err: legacy.ErrUpstreamStatus{Code: 500}, wantStatus: 502},
+		{desc: "must map legacy decode", err: legacy.ErrDecode{Err: errors.New("eof")}, wantStatus: 502},
+		{desc: "must return internal server error", err: errors.New("unknown"), wantStatus: 500},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			handler.ResponseError(tt.err, c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
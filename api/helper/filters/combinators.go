@@ -0,0 +1,80 @@
+package filters
+
+import (
+	"maga-auctions/entity"
+)
+
+type and struct {
+	Filters []Filter
+}
+
+type or struct {
+	Filters []Filter
+}
+
+type not struct {
+	Filter Filter
+}
+
+// And filter keeps the vehicles every filter keeps
+func And(fs ...Filter) Filter {
+	return &and{
+		Filters: fs,
+	}
+}
+
+// Or filter keeps the vehicles any filter keeps
+func Or(fs ...Filter) Filter {
+	return &or{
+		Filters: fs,
+	}
+}
+
+// Not filter keeps the vehicles the filter drops
+func Not(f Filter) Filter {
+	return &not{
+		Filter: f,
+	}
+}
+
+// Rule filter and
+func (a and) Rule(vehicle entity.Vehicle) bool {
+	for _, f := range a.Filters {
+		if !f.Rule(vehicle) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply filter
+func (a and) Apply(input *[]entity.Vehicle) {
+	filterApply(input, a.Rule)
+}
+
+// Rule filter or
+func (o or) Rule(vehicle entity.Vehicle) bool {
+	for _, f := range o.Filters {
+		if f.Rule(vehicle) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply filter
+func (o or) Apply(input *[]entity.Vehicle) {
+	filterApply(input, o.Rule)
+}
+
+// Rule filter not
+func (n not) Rule(vehicle entity.Vehicle) bool {
+	return !n.Filter.Rule(vehicle)
+}
+
+// Apply filter
+func (n not) Apply(input *[]entity.Vehicle) {
+	filterApply(input, n.Rule)
+}
@@ -0,0 +1,44 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombinators_Rule(t *testing.T) {
+	fiat := filters.NewVehicleBrand("fiat")
+	uno := filters.NewVehicleModel("uno")
+
+	testCases := []struct {
+		desc   string
+		filter filters.Filter
+		ve     entity.Vehicle
+		want   bool
+	}{
+		{desc: "must keep when every filter keeps", filter: filters.And(fiat, uno), ve: entity.Vehicle{Brand: "FIAT", Model: "UNO"}, want: true},
+		{desc: "must drop when a filter drops", filter: filters.And(fiat, uno), ve: entity.Vehicle{Brand: "FIAT", Model: "PALIO"}},
+		{desc: "must keep when any filter keeps", filter: filters.Or(fiat, uno), ve: entity.Vehicle{Brand: "RENAULT", Model: "UNO"}, want: true},
+		{desc: "must drop when no filter keeps", filter: filters.Or(fiat, uno), ve: entity.Vehicle{Brand: "RENAULT", Model: "CLIO"}},
+		{desc: "must negate the filter", filter: filters.Not(fiat), ve: entity.Vehicle{Brand: "RENAULT"}, want: true},
+		{desc: "must keep without filters", filter: filters.And(), ve: entity.Vehicle{}, want: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Rule(tt.ve))
+		})
+	}
+}
+
+func TestCombinators_Apply(t *testing.T) {
+	t.Run("must filter by the combined filters", func(t *testing.T) {
+		items := &[]entity.Vehicle{{Brand: "FIAT", Model: "UNO"}, {Brand: "FIAT", Model: "PALIO"}, {Brand: "HONDA", Model: "FIT"}}
+
+		filters.Or(filters.Not(filters.NewVehicleBrand("fiat")), filters.NewVehicleModel("uno")).Apply(items)
+
+		assert.Equal(t, []entity.Vehicle{{Brand: "FIAT", Model: "UNO"}, {Brand: "HONDA", Model: "FIT"}}, *items)
+	})
+}
@@ -0,0 +1,41 @@
+package filters
+
+import (
+	"errors"
+	"maga-auctions/entity"
+	"time"
+)
+
+// vehicleBidDateBetween filters vehicles by the date of the current bid
+type vehicleBidDateBetween struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewVehicleBidDateBetween filters by the bid date, a zero bound is left open and the vehicles without a bid date are left out
+func NewVehicleBidDateBetween(from, to time.Time) (Filter, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, errors.New("bid date to cannot be before from")
+	}
+
+	return &vehicleBidDateBetween{
+		From: from,
+		To:   to,
+	}, nil
+}
+
+// Rule filter bid date
+func (v vehicleBidDateBetween) Rule(vehicle entity.Vehicle) bool {
+	date := vehicle.Bid.Date
+
+	if !vehicle.Bid.Placed() || date.IsZero() {
+		return false
+	}
+
+	return (v.From.IsZero() || !date.Before(v.From)) && (v.To.IsZero() || !date.After(v.To))
+}
+
+// Apply filter
+func (v vehicleBidDateBetween) Apply(input *[]entity.Vehicle) {
+	filterApply(input, v.Rule)
+}
@@ -0,0 +1,62 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleBidDateBetween_Rule(t *testing.T) {
+	day := time.Date(2020, 8, 21, 12, 0, 0, 0, time.UTC)
+	placed := entity.NewMoney(1000, "")
+
+	testCases := []struct {
+		desc     string
+		from, to time.Time
+		bid      entity.Bid
+		want     bool
+	}{
+		{desc: "must keep the date inside the range", from: day.Add(-time.Hour), to: day.Add(time.Hour), bid: entity.Bid{Date: day, Value: placed}, want: true},
+		{desc: "must keep the date on the bounds", from: day, to: day, bid: entity.Bid{Date: day, Value: placed}, want: true},
+		{desc: "must drop the date before from", from: day.Add(time.Hour), bid: entity.Bid{Date: day, Value: placed}},
+		{desc: "must drop the date after to", to: day.Add(-time.Hour), bid: entity.Bid{Date: day, Value: placed}},
+		{desc: "must keep any date without bounds", bid: entity.Bid{Date: day, Value: placed}, want: true},
+		{desc: "must drop the vehicle without bids", bid: entity.Bid{Date: day}},
+		{desc: "must drop the bid without a date", bid: entity.Bid{Value: placed}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ft, err := filters.NewVehicleBidDateBetween(tt.from, tt.to)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, ft.Rule(entity.Vehicle{Bid: tt.bid}))
+		})
+	}
+}
+
+func TestVehicleBidDateBetween_RuleError(t *testing.T) {
+	t.Run("must return error when bid date to is before from", func(t *testing.T) {
+		day := time.Date(2020, 8, 21, 12, 0, 0, 0, time.UTC)
+		_, err := filters.NewVehicleBidDateBetween(day, day.Add(-time.Second))
+
+		assert.EqualError(t, err, "bid date to cannot be before from")
+	})
+}
+
+func TestVehicleBidDateBetween_Apply(t *testing.T) {
+	t.Run("must filter by bid date", func(t *testing.T) {
+		day := time.Date(2020, 8, 21, 12, 0, 0, 0, time.UTC)
+		ve1 := entity.Vehicle{ID: 1, Bid: entity.Bid{Date: day, Value: entity.NewMoney(1000, "")}}
+		ve2 := entity.Vehicle{ID: 2, Bid: entity.Bid{Date: day.AddDate(0, 0, 2), Value: entity.NewMoney(1000, "")}}
+		items := &[]entity.Vehicle{ve1, ve2}
+
+		ft, _ := filters.NewVehicleBidDateBetween(day.AddDate(0, 0, 1), time.Time{})
+		ft.Apply(items)
+
+		assert.Equal(t, []entity.Vehicle{ve2}, *items)
+	})
+}
@@ -0,0 +1,59 @@
+package filters
+
+import (
+	"errors"
+	"maga-auctions/entity"
+)
+
+// vehicleBidValueBetween filters vehicles by the value of the current bid
+type vehicleBidValueBetween struct {
+	Min *entity.Money
+	Max *entity.Money
+}
+
+// NewVehicleBidValueBetween filters by the bid value, a nil bound is left open and the vehicles without bids are left out
+func NewVehicleBidValueBetween(min, max *entity.Money) (Filter, error) {
+	if min != nil && max != nil {
+		c, err := max.Compare(*min)
+
+		if err != nil {
+			return nil, errors.New("bid value min and max must be in the same currency")
+		}
+
+		if c < 0 {
+			return nil, errors.New("bid value max cannot be less than min")
+		}
+	}
+
+	return &vehicleBidValueBetween{
+		Min: min,
+		Max: max,
+	}, nil
+}
+
+// Rule filter bid value
+func (v vehicleBidValueBetween) Rule(vehicle entity.Vehicle) bool {
+	if !vehicle.Bid.Placed() {
+		return false
+	}
+
+	value := vehicle.Bid.Value
+
+	// a bid in another currency than the bounds is out of the range
+	within := func(bound *entity.Money, ok func(c int) bool) bool {
+		if bound == nil {
+			return true
+		}
+
+		c, err := value.Compare(*bound)
+
+		return err == nil && ok(c)
+	}
+
+	return within(v.Min, func(c int) bool { return c >= 0 }) && within(v.Max, func(c int) bool { return c <= 0 })
+}
+
+// Apply filter
+func (v vehicleBidValueBetween) Apply(input *[]entity.Vehicle) {
+	filterApply(input, v.Rule)
+}
@@ -0,0 +1,75 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func money(amount int64) *entity.Money {
+	m := entity.NewMoney(amount, entity.DefaultCurrency)
+	return &m
+}
+
+func TestVehicleBidValueBetween_Rule(t *testing.T) {
+	testCases := []struct {
+		desc, currency string
+		min, max       *entity.Money
+		bid            int64
+		want           bool
+	}{
+		{desc: "must keep the value inside the range", min: money(1000), max: money(5000), bid: 3000, want: true},
+		{desc: "must keep the value on the bounds", min: money(3000), max: money(3000), bid: 3000, want: true},
+		{desc: "must drop the value below the min", min: money(5000), bid: 3000},
+		{desc: "must drop the value above the max", max: money(1000), bid: 3000},
+		{desc: "must keep any value without bounds", bid: 3000, want: true},
+		{desc: "must drop the vehicle without bids", min: money(0), bid: 0},
+		{desc: "must drop the bid in another currency", min: money(1000), bid: 3000, currency: "USD"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ft, err := filters.NewVehicleBidValueBetween(tt.min, tt.max)
+			currency := tt.currency
+
+			if currency == "" {
+				currency = entity.DefaultCurrency
+			}
+
+			ve := entity.Vehicle{Bid: entity.Bid{Value: entity.NewMoney(tt.bid, currency)}}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, ft.Rule(ve))
+		})
+	}
+}
+
+func TestVehicleBidValueBetween_RuleError(t *testing.T) {
+	t.Run("must return error when bid value max is less than min", func(t *testing.T) {
+		_, err := filters.NewVehicleBidValueBetween(money(5000), money(1000))
+
+		assert.EqualError(t, err, "bid value max cannot be less than min")
+	})
+
+	t.Run("must return error when bid value min and max are in different currencies", func(t *testing.T) {
+		max := entity.NewMoney(1000, "USD")
+		_, err := filters.NewVehicleBidValueBetween(money(5000), &max)
+
+		assert.EqualError(t, err, "bid value min and max must be in the same currency")
+	})
+}
+
+func TestVehicleBidValueBetween_Apply(t *testing.T) {
+	t.Run("must filter by bid value", func(t *testing.T) {
+		ve1 := entity.Vehicle{ID: 1, Bid: entity.Bid{Value: entity.NewMoney(1000, "")}}
+		ve2 := entity.Vehicle{ID: 2, Bid: entity.Bid{Value: entity.NewMoney(9000, "")}}
+		items := &[]entity.Vehicle{ve1, ve2}
+
+		ft, _ := filters.NewVehicleBidValueBetween(money(5000), nil)
+		ft.Apply(items)
+
+		assert.Equal(t, []entity.Vehicle{ve2}, *items)
+	})
+}
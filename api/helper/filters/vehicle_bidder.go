@@ -0,0 +1,27 @@
+package filters
+
+import (
+	"maga-auctions/entity"
+	"strings"
+)
+
+type vehicleBidder struct {
+	User string
+}
+
+// NewVehicleBidder filters the vehicles where the user holds the top bid, the case is ignored
+func NewVehicleBidder(user string) Filter {
+	return &vehicleBidder{
+		User: strings.TrimSpace(user),
+	}
+}
+
+// Rule filter bidder
+func (v vehicleBidder) Rule(vehicle entity.Vehicle) bool {
+	return vehicle.Bid.Placed() && strings.EqualFold(strings.TrimSpace(vehicle.Bid.User), v.User)
+}
+
+// Apply filter
+func (v vehicleBidder) Apply(input *[]entity.Vehicle) {
+	filterApply(input, v.Rule)
+}
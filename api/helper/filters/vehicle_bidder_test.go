@@ -0,0 +1,42 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleBidder_Rule(t *testing.T) {
+	placed := entity.NewMoney(1000, "")
+
+	testCases := []struct {
+		desc, user string
+		bid        entity.Bid
+		want       bool
+	}{
+		{desc: "must keep the top bid of the user", user: "Michaelnf", bid: entity.Bid{User: "Michaelnf", Value: placed}, want: true},
+		{desc: "must ignore the case and the spaces", user: " michaelNF ", bid: entity.Bid{User: "Michaelnf", Value: placed}, want: true},
+		{desc: "must drop the top bid of another user", user: "Sorico1", bid: entity.Bid{User: "Michaelnf", Value: placed}},
+		{desc: "must drop the vehicle without bids", user: "-", bid: entity.Bid{User: "-"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, filters.NewVehicleBidder(tt.user).Rule(entity.Vehicle{Bid: tt.bid}))
+		})
+	}
+}
+
+func TestVehicleBidder_Apply(t *testing.T) {
+	t.Run("must filter by bidder", func(t *testing.T) {
+		ve1 := entity.Vehicle{ID: 1, Bid: entity.Bid{User: "Michaelnf", Value: entity.NewMoney(1000, "")}}
+		ve2 := entity.Vehicle{ID: 2, Bid: entity.Bid{User: "Sorico1", Value: entity.NewMoney(1000, "")}}
+		items := &[]entity.Vehicle{ve1, ve2}
+
+		filters.NewVehicleBidder("michaelnf").Apply(items)
+
+		assert.Equal(t, []entity.Vehicle{ve1}, *items)
+	})
+}
@@ -0,0 +1,26 @@
+package filters
+
+import (
+	"maga-auctions/entity"
+)
+
+type vehicleHasBids struct {
+	Has bool
+}
+
+// NewVehicleHasBids filters vehicles by whether a bid was placed on them
+func NewVehicleHasBids(has bool) Filter {
+	return &vehicleHasBids{
+		Has: has,
+	}
+}
+
+// Rule filter has bids
+func (v vehicleHasBids) Rule(vehicle entity.Vehicle) bool {
+	return vehicle.Bid.Placed() == v.Has
+}
+
+// Apply filter
+func (v vehicleHasBids) Apply(input *[]entity.Vehicle) {
+	filterApply(input, v.Rule)
+}
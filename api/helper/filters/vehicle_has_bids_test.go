@@ -0,0 +1,44 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleHasBids_Rule(t *testing.T) {
+	withBid := entity.Vehicle{Bid: entity.Bid{Value: entity.NewMoney(1000, ""), User: "Michaelnf"}}
+	withoutBid := entity.Vehicle{Bid: entity.Bid{User: "-"}}
+
+	testCases := []struct {
+		desc string
+		has  bool
+		ve   entity.Vehicle
+		want bool
+	}{
+		{desc: "must keep the vehicle with bids", has: true, ve: withBid, want: true},
+		{desc: "must drop the vehicle without bids", has: true, ve: withoutBid},
+		{desc: "must keep the vehicle without bids", has: false, ve: withoutBid, want: true},
+		{desc: "must drop the vehicle with bids", has: false, ve: withBid},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, filters.NewVehicleHasBids(tt.has).Rule(tt.ve))
+		})
+	}
+}
+
+func TestVehicleHasBids_Apply(t *testing.T) {
+	t.Run("must filter by has bids", func(t *testing.T) {
+		ve1 := entity.Vehicle{ID: 1, Bid: entity.Bid{Value: entity.NewMoney(1000, "")}}
+		ve2 := entity.Vehicle{ID: 2}
+		items := &[]entity.Vehicle{ve1, ve2}
+
+		filters.NewVehicleHasBids(false).Apply(items)
+
+		assert.Equal(t, []entity.Vehicle{ve2}, *items)
+	})
+}
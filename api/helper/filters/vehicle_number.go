@@ -0,0 +1,63 @@
+package filters
+
+import (
+	"fmt"
+	"maga-auctions/entity"
+)
+
+// vehicleNumbers are the number fields a vehicle can be filtered by
+var vehicleNumbers = map[string]func(entity.Vehicle) int{
+	"id":                func(v entity.Vehicle) int { return v.ID },
+	"modelYear":         func(v entity.Vehicle) int { return v.ModelYear },
+	"manufacturingYear": func(v entity.Vehicle) int { return v.ManufacturingYear },
+}
+
+// numberOps are the comparisons a number field accepts
+var numberOps = map[string]func(a, b int) bool{
+	"=":  func(a, b int) bool { return a == b },
+	"<":  func(a, b int) bool { return a < b },
+	"<=": func(a, b int) bool { return a <= b },
+	">":  func(a, b int) bool { return a > b },
+	">=": func(a, b int) bool { return a >= b },
+}
+
+type vehicleNumber struct {
+	Field   string
+	Op      string
+	Value   int
+	value   func(entity.Vehicle) int
+	compare func(a, b int) bool
+}
+
+// NewVehicleNumber filters a number field by a comparison with the value, the op is one of = < <= > >=
+func NewVehicleNumber(field, op string, value int) (Filter, error) {
+	get, ok := vehicleNumbers[field]
+
+	if !ok {
+		return nil, fmt.Errorf("%s is not a number field", field)
+	}
+
+	compare, ok := numberOps[op]
+
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be compared with %q", field, op)
+	}
+
+	return &vehicleNumber{
+		Field:   field,
+		Op:      op,
+		Value:   value,
+		value:   get,
+		compare: compare,
+	}, nil
+}
+
+// Rule filter number
+func (v vehicleNumber) Rule(vehicle entity.Vehicle) bool {
+	return v.compare(v.value(vehicle), v.Value)
+}
+
+// Apply filter
+func (v vehicleNumber) Apply(input *[]entity.Vehicle) {
+	filterApply(input, v.Rule)
+}
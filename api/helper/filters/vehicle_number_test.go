@@ -0,0 +1,63 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleNumber_Rule(t *testing.T) {
+	ve := entity.Vehicle{ID: 180, ModelYear: 2015, ManufacturingYear: 2014}
+
+	testCases := []struct {
+		desc, field, op string
+		value           int
+		want            bool
+	}{
+		{desc: "must compare equal", field: "id", op: "=", value: 180, want: true},
+		{desc: "must compare less", field: "modelYear", op: "<", value: 2015},
+		{desc: "must compare less or equal", field: "modelYear", op: "<=", value: 2015, want: true},
+		{desc: "must compare greater", field: "manufacturingYear", op: ">", value: 2013, want: true},
+		{desc: "must compare greater or equal", field: "manufacturingYear", op: ">=", value: 2015},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ft, err := filters.NewVehicleNumber(tt.field, tt.op, tt.value)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, ft.Rule(ve))
+		})
+	}
+}
+
+func TestVehicleNumber_Apply(t *testing.T) {
+	t.Run("must filter by the comparison", func(t *testing.T) {
+		items := &[]entity.Vehicle{{ModelYear: 2010}, {ModelYear: 2015}, {ModelYear: 2020}}
+
+		ft, _ := filters.NewVehicleNumber("modelYear", ">=", 2015)
+		ft.Apply(items)
+
+		assert.Len(t, *items, 2)
+	})
+}
+
+func TestVehicleNumber_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, field, op, want string
+	}{
+		{desc: "must return error when the field is not a number", field: "brand", op: "=", want: "brand is not a number field"},
+		{desc: "must return error when the op is unknown", field: "modelYear", op: "!=", want: `modelYear cannot be compared with "!="`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ft, err := filters.NewVehicleNumber(tt.field, tt.op, 2015)
+
+			assert.Nil(t, ft)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
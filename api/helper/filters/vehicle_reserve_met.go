@@ -0,0 +1,26 @@
+package filters
+
+import (
+	"maga-auctions/entity"
+)
+
+type vehicleReserveMet struct {
+	Met bool
+}
+
+// NewVehicleReserveMet filters vehicles by whether the current bid meets the reserve price
+func NewVehicleReserveMet(met bool) Filter {
+	return &vehicleReserveMet{
+		Met: met,
+	}
+}
+
+// Rule filter reserve met
+func (v vehicleReserveMet) Rule(vehicle entity.Vehicle) bool {
+	return vehicle.ReserveMet == v.Met
+}
+
+// Apply filter
+func (v vehicleReserveMet) Apply(input *[]entity.Vehicle) {
+	filterApply(input, v.Rule)
+}
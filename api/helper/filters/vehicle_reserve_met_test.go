@@ -0,0 +1,31 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleReserveMet_Rule(t *testing.T) {
+	t.Run("must validate whether the reserve is met", func(t *testing.T) {
+		ft := filters.NewVehicleReserveMet(true)
+
+		assert.True(t, ft.Rule(entity.Vehicle{ReserveMet: true}))
+		assert.False(t, ft.Rule(entity.Vehicle{ReserveMet: false}))
+	})
+}
+
+func TestVehicleReserveMet_Apply(t *testing.T) {
+	t.Run("must filter by reserve met", func(t *testing.T) {
+		ve1 := entity.Vehicle{ID: 1, ReserveMet: true}
+		ve2 := entity.Vehicle{ID: 2, ReserveMet: false}
+		items := &[]entity.Vehicle{ve1, ve2}
+
+		ft := filters.NewVehicleReserveMet(false)
+		ft.Apply(items)
+
+		assert.Equal(t, []entity.Vehicle{ve2}, *items)
+	})
+}
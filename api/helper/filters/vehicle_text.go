@@ -0,0 +1,75 @@
+package filters
+
+import (
+	"fmt"
+	"maga-auctions/entity"
+	"strings"
+)
+
+// vehicleTexts are the text fields a vehicle can be filtered by
+var vehicleTexts = map[string]func(entity.Vehicle) string{
+	"brand":            func(v entity.Vehicle) string { return v.Brand },
+	"model":            func(v entity.Vehicle) string { return v.Model },
+	"lot.id":           func(v entity.Vehicle) string { return v.Lot.ID },
+	"lot.vehicleLotId": func(v entity.Vehicle) string { return v.Lot.VehicleLotID },
+	"bid.user":         func(v entity.Vehicle) string { return strings.TrimSpace(v.Bid.User) },
+}
+
+type vehicleText struct {
+	Field   string
+	Pattern string
+	value   func(entity.Vehicle) string
+}
+
+// NewVehicleText filters a text field by a pattern, * matches any letters and the case is ignored
+func NewVehicleText(field, pattern string) (Filter, error) {
+	value, ok := vehicleTexts[field]
+
+	if !ok {
+		return nil, fmt.Errorf("%s is not a text field", field)
+	}
+
+	return &vehicleText{
+		Field:   field,
+		Pattern: strings.ToUpper(pattern),
+		value:   value,
+	}, nil
+}
+
+// Rule filter text
+func (v vehicleText) Rule(vehicle entity.Vehicle) bool {
+	return match(v.Pattern, strings.ToUpper(v.value(vehicle)))
+}
+
+// Apply filter
+func (v vehicleText) Apply(input *[]entity.Vehicle) {
+	filterApply(input, v.Rule)
+}
+
+// match tells whether the text fits the pattern, each * stands for any letters
+func match(pattern, text string) bool {
+	parts := strings.Split(pattern, "*")
+
+	if len(parts) == 1 {
+		return pattern == text
+	}
+
+	if !strings.HasPrefix(text, parts[0]) {
+		return false
+	}
+
+	text = text[len(parts[0]):]
+	last := parts[len(parts)-1]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(text, part)
+
+		if i < 0 {
+			return false
+		}
+
+		text = text[i+len(part):]
+	}
+
+	return strings.HasSuffix(text, last)
+}
@@ -0,0 +1,55 @@
+package filters_test
+
+import (
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleText_Rule(t *testing.T) {
+	ve := entity.Vehicle{Brand: "HONDA", Model: "CIVIC SEDAN LXR", Bid: entity.Bid{User: "Michaelnf"}}
+
+	testCases := []struct {
+		desc, field, pattern string
+		want                 bool
+	}{
+		{desc: "must match the whole text", field: "brand", pattern: "honda", want: true},
+		{desc: "must not match a part of the text", field: "brand", pattern: "hond"},
+		{desc: "must match the prefix", field: "model", pattern: "civic*", want: true},
+		{desc: "must match the suffix", field: "model", pattern: "*lxr", want: true},
+		{desc: "must match the middle", field: "model", pattern: "c*sedan*r", want: true},
+		{desc: "must not match the parts out of order", field: "model", pattern: "*lxr*sedan*"},
+		{desc: "must match anything", field: "bid.user", pattern: "*", want: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ft, err := filters.NewVehicleText(tt.field, tt.pattern)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, ft.Rule(ve))
+		})
+	}
+}
+
+func TestVehicleText_Apply(t *testing.T) {
+	t.Run("must filter by the pattern", func(t *testing.T) {
+		items := &[]entity.Vehicle{{Lot: entity.Lot{ID: "0161"}}, {Lot: entity.Lot{ID: "0033"}}}
+
+		ft, _ := filters.NewVehicleText("lot.id", "01*")
+		ft.Apply(items)
+
+		assert.Len(t, *items, 1)
+	})
+}
+
+func TestVehicleText_Errors(t *testing.T) {
+	t.Run("must return error when the field is not a text", func(t *testing.T) {
+		ft, err := filters.NewVehicleText("modelYear", "2015")
+
+		assert.Nil(t, ft)
+		assert.EqualError(t, err, "modelYear is not a text field")
+	})
+}
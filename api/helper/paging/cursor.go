@@ -0,0 +1,51 @@
+package paging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Cursor points after the last item of a page, the clients get it encoded and opaque
+type Cursor struct {
+	Offset int    `json:"o"`
+	Key    string `json:"k"`
+}
+
+// Encode turns the cursor into an url safe token
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor reads a token made by Encode
+func DecodeCursor(token string) (Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil || json.Unmarshal(b, &c) != nil || c.Offset < 1 || c.Key == "" {
+		return Cursor{}, errors.New("cursor is invalid")
+	}
+
+	return c, nil
+}
+
+// Start finds where the page after the cursor starts, it follows the item of the key when items before it came or went
+func (c Cursor) Start(total int, keyOf func(i int) string) int {
+	if c.Offset <= total && keyOf(c.Offset-1) == c.Key {
+		return c.Offset
+	}
+
+	for i := 0; i < total; i++ {
+		if keyOf(i) == c.Key {
+			return i + 1
+		}
+	}
+
+	if c.Offset > total {
+		return total
+	}
+
+	return c.Offset
+}
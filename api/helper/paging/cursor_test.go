@@ -0,0 +1,49 @@
+package paging_test
+
+import (
+	"maga-auctions/api/helper/paging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("must encode and decode the cursor", func(t *testing.T) {
+		c := paging.Cursor{Offset: 20, Key: "760"}
+
+		got, err := paging.DecodeCursor(c.Encode())
+
+		assert.Nil(t, err)
+		assert.Equal(t, c, got)
+	})
+
+	t.Run("must return error when the cursor is invalid", func(t *testing.T) {
+		for _, token := range []string{"", "!", "e30", paging.Cursor{Key: "760"}.Encode()} {
+			_, err := paging.DecodeCursor(token)
+
+			assert.EqualError(t, err, "cursor is invalid", token)
+		}
+	})
+}
+
+func TestCursor_Start(t *testing.T) {
+	keys := []string{"1", "2", "3", "4", "5"}
+	keyOf := func(i int) string { return keys[i] }
+
+	testCases := []struct {
+		desc   string
+		cursor paging.Cursor
+		want   int
+	}{
+		{desc: "must start at the offset", cursor: paging.Cursor{Offset: 2, Key: "2"}, want: 2},
+		{desc: "must follow the item when items came before it", cursor: paging.Cursor{Offset: 2, Key: "4"}, want: 4},
+		{desc: "must keep the offset when the item is gone", cursor: paging.Cursor{Offset: 2, Key: "9"}, want: 2},
+		{desc: "must end when the offset is past the items", cursor: paging.Cursor{Offset: 9, Key: "9"}, want: 5},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cursor.Start(len(keys), keyOf))
+		})
+	}
+}
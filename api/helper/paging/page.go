@@ -0,0 +1,80 @@
+package paging
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Page sizes, see SetSizes
+var (
+	DefaultSize = 20
+	MaxSize     = 100
+)
+
+// SetSizes bounds the pages, zero keeps the current size
+func SetSizes(defaultSize, maxSize int) error {
+	if defaultSize == 0 {
+		defaultSize = DefaultSize
+	}
+
+	if maxSize == 0 {
+		maxSize = MaxSize
+	}
+
+	if defaultSize < 1 || maxSize < defaultSize {
+		return fmt.Errorf("page sizes %d/%d are invalid", defaultSize, maxSize)
+	}
+
+	DefaultSize, MaxSize = defaultSize, maxSize
+
+	return nil
+}
+
+// Page of a list, numbered from 1
+type Page struct {
+	Number int `json:"page"`
+	Size   int `json:"pageSize"`
+}
+
+// New validates the page, zero values take the first page and the default size
+func New(number, size int) (Page, error) {
+	if number == 0 {
+		number = 1
+	}
+
+	if size == 0 {
+		size = DefaultSize
+	}
+
+	if number < 0 {
+		return Page{}, errors.New("page is invalid")
+	}
+
+	if size < 0 || size > MaxSize {
+		return Page{}, errors.New("page size is invalid")
+	}
+
+	return Page{Number: number, Size: size}, nil
+}
+
+// Bounds returns the slice bounds of the page in a list with total items
+func (p Page) Bounds(total int) (int, int) {
+	if p.Number <= 0 || p.Size <= 0 {
+		p, _ = New(p.Number, p.Size)
+	}
+
+	// past the end, checked before multiplying so a huge page number cannot overflow
+	if p.Number-1 > total/p.Size {
+		return total, total
+	}
+
+	start := (p.Number - 1) * p.Size
+
+	end := start + p.Size
+
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
@@ -0,0 +1,80 @@
+package paging_test
+
+import (
+	"maga-auctions/api/helper/paging"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		number, size int
+		want         paging.Page
+		wantErr      string
+	}{
+		{desc: "must use the defaults", want: paging.Page{Number: 1, Size: paging.DefaultSize}},
+		{desc: "must keep the page", number: 3, size: 5, want: paging.Page{Number: 3, Size: 5}},
+		{desc: "must return error when page is negative", number: -1, wantErr: "page is invalid"},
+		{desc: "must return error when size is negative", size: -1, wantErr: "page size is invalid"},
+		{desc: "must return error when size is too big", size: paging.MaxSize + 1, wantErr: "page size is invalid"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			p, err := paging.New(tt.number, tt.size)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, p)
+		})
+	}
+}
+
+func TestBounds(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		page              paging.Page
+		total, start, end int
+	}{
+		{desc: "must return the first page", page: paging.Page{Number: 1, Size: 2}, total: 5, start: 0, end: 2},
+		{desc: "must return the last page", page: paging.Page{Number: 3, Size: 2}, total: 5, start: 4, end: 5},
+		{desc: "must return an empty page after the end", page: paging.Page{Number: 4, Size: 2}, total: 5, start: 5, end: 5},
+		{desc: "must use the defaults for a zero page", total: 30, start: 0, end: paging.DefaultSize},
+		{desc: "must return an empty page right after the end", page: paging.Page{Number: 3, Size: 2}, total: 4, start: 4, end: 4},
+		{desc: "must not overflow on the max page", page: paging.Page{Number: math.MaxInt64, Size: 20}, total: 68, start: 68, end: 68},
+		{desc: "must not overflow on the max page and size", page: paging.Page{Number: math.MaxInt64, Size: math.MaxInt64}, total: 68, start: 68, end: 68},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			start, end := tt.page.Bounds(tt.total)
+
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestSetSizes(t *testing.T) {
+	defaultSize, maxSize := paging.DefaultSize, paging.MaxSize
+	defer func() { _ = paging.SetSizes(defaultSize, maxSize) }()
+
+	assert.EqualError(t, paging.SetSizes(50, 10), "page sizes 50/10 are invalid")
+	assert.EqualError(t, paging.SetSizes(-1, 10), "page sizes -1/10 are invalid")
+	assert.Equal(t, defaultSize, paging.DefaultSize)
+
+	assert.Nil(t, paging.SetSizes(10, 0))
+	assert.Equal(t, 10, paging.DefaultSize)
+	assert.Equal(t, maxSize, paging.MaxSize)
+
+	p, err := paging.New(0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, p.Size)
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Node is a piece of a parsed expression
+type Node interface {
+	// Pos is the 1-based position of the node in the expression
+	Pos() int
+	// String writes the node back as an expression
+	String() string
+}
+
+// Binary joins two expressions with AND or OR
+type Binary struct {
+	Op          string
+	Left, Right Node
+	pos         int
+}
+
+// Not negates an expression
+type Not struct {
+	Expr Node
+	pos  int
+}
+
+// Term compares a field with a value, like brand:FIAT or modelYear>=2015
+type Term struct {
+	Field, Op, Value   string
+	pos, opPos, valPos int
+}
+
+// Pos of the operator
+func (b *Binary) Pos() int { return b.pos }
+
+// String of the expression, with the parentheses the precedence needs
+func (b *Binary) String() string {
+	left, right := b.Left.String(), b.Right.String()
+
+	if l, ok := b.Left.(*Binary); ok && l.Op != b.Op && b.Op == "AND" {
+		left = "(" + left + ")"
+	}
+
+	if _, ok := b.Right.(*Binary); ok {
+		right = "(" + right + ")"
+	}
+
+	return left + " " + b.Op + " " + right
+}
+
+// Pos of the NOT
+func (n *Not) Pos() int { return n.pos }
+
+// String of the negated expression
+func (n *Not) String() string {
+	if _, ok := n.Expr.(*Binary); ok {
+		return "NOT (" + n.Expr.String() + ")"
+	}
+
+	return "NOT " + n.Expr.String()
+}
+
+// Pos of the field
+func (t *Term) Pos() int { return t.pos }
+
+// String of the term, the value is quoted when it is not a plain word
+func (t *Term) String() string {
+	return t.Field + t.Op + quote(t.Value)
+}
+
+// quote writes the value so that lex reads it back the same
+func quote(value string) string {
+	plain := value != ""
+
+	for _, r := range value {
+		if unicode.IsSpace(r) || strings.ContainsRune(special, r) {
+			plain = false
+			break
+		}
+	}
+
+	if plain {
+		return value
+	}
+
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+
+	return `"` + value + `"`
+}
@@ -0,0 +1,228 @@
+package query
+
+import (
+	"fmt"
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	numberField
+	moneyField
+	dateField
+	boolField
+)
+
+// fields are the fields an expression can filter by
+var fields = map[string]fieldKind{
+	"brand":             textField,
+	"model":             textField,
+	"lot.id":            textField,
+	"lot.vehicleLotId":  textField,
+	"bid.user":          textField,
+	"id":                numberField,
+	"modelYear":         numberField,
+	"manufacturingYear": numberField,
+	"bid.value":         moneyField,
+	"bid.date":          dateField,
+	"reserveMet":        boolField,
+	"hasBids":           boolField,
+}
+
+// flags build the filters of the bool fields
+var flags = map[string]func(bool) filters.Filter{
+	"reserveMet": filters.NewVehicleReserveMet,
+	"hasBids":    filters.NewVehicleHasBids,
+}
+
+// dateLayouts are the layouts a date value can be written in, a quoted RFC 3339 or a day
+var dateLayouts = []string{time.RFC3339, dayLayout}
+
+const dayLayout = "2006-01-02"
+
+// Filter parses the expression and compiles it into a filter
+func Filter(q string) (filters.Filter, error) {
+	node, err := Parse(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Compile(node)
+}
+
+// Compile turns the parsed expression into filters, the errors point at the term that cannot be filtered
+func Compile(node Node) (filters.Filter, error) {
+	switch n := node.(type) {
+	case *Binary:
+		left, err := Compile(n.Left)
+
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := Compile(n.Right)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if n.Op == "OR" {
+			return filters.Or(left, right), nil
+		}
+
+		return filters.And(left, right), nil
+	case *Not:
+		expr, err := Compile(n.Expr)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return filters.Not(expr), nil
+	case *Term:
+		return compileTerm(n)
+	}
+
+	return nil, fmt.Errorf("unknown node %T", node)
+}
+
+func compileTerm(t *Term) (filters.Filter, error) {
+	kind, ok := fields[t.Field]
+
+	if !ok {
+		return nil, &SyntaxError{Position: t.pos, Message: fmt.Sprintf("unknown field %q", t.Field)}
+	}
+
+	op := t.Op
+
+	if op == ":" {
+		op = "="
+	}
+
+	if (kind == textField || kind == boolField) && op != "=" {
+		return nil, &SyntaxError{Position: t.opPos, Message: fmt.Sprintf("%s cannot be compared with %q", t.Field, t.Op)}
+	}
+
+	switch kind {
+	case numberField:
+		n, err := strconv.Atoi(t.Value)
+
+		if err != nil {
+			return nil, &SyntaxError{Position: t.valPos, Message: fmt.Sprintf("%s needs a number", t.Field)}
+		}
+
+		return filters.NewVehicleNumber(t.Field, op, n)
+	case moneyField:
+		m, err := entity.ParseMoney(t.Value, entity.DefaultCurrency)
+
+		if err != nil || m.IsNegative() {
+			return nil, &SyntaxError{Position: t.valPos, Message: fmt.Sprintf("%s needs an amount", t.Field)}
+		}
+
+		return bidRange(op, func(from, to bool) (filters.Filter, error) {
+			var min, max *entity.Money
+
+			if from {
+				min = &m
+			}
+
+			if to {
+				max = &m
+			}
+
+			return filters.NewVehicleBidValueBetween(min, max)
+		})
+	case dateField:
+		first, last, err := parseDate(t.Value)
+
+		if err != nil {
+			return nil, &SyntaxError{Position: t.valPos, Message: fmt.Sprintf("%s needs a date like 2020-08-22 or \"2020-08-22T09:48:00Z\"", t.Field)}
+		}
+
+		return bidRange(op, func(from, to bool) (filters.Filter, error) {
+			var min, max time.Time
+
+			if from {
+				min = first
+			}
+
+			if to {
+				max = last
+			}
+
+			return filters.NewVehicleBidDateBetween(min, max)
+		})
+	case boolField:
+		b, err := strconv.ParseBool(strings.ToLower(t.Value))
+
+		if err != nil {
+			return nil, &SyntaxError{Position: t.valPos, Message: fmt.Sprintf("%s needs true or false", t.Field)}
+		}
+
+		return flags[t.Field](b), nil
+	}
+
+	if t.Field == "bid.user" {
+		// the same user the bidder filter matches, of a placed bid and with no spaces around
+		text, err := filters.NewVehicleText(t.Field, strings.TrimSpace(t.Value))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return filters.And(filters.NewVehicleHasBids(true), text), nil
+	}
+
+	return filters.NewVehicleText(t.Field, t.Value)
+}
+
+// bidRange turns the comparison into the bounds of a bid range, the strict ones leave the value itself out
+func bidRange(op string, between func(from, to bool) (filters.Filter, error)) (filters.Filter, error) {
+	switch op {
+	case "=":
+		return between(true, true)
+	case ">=":
+		return between(true, false)
+	case "<=":
+		return between(false, true)
+	}
+
+	open, err := between(op == ">", op == "<")
+
+	if err != nil {
+		return nil, err
+	}
+
+	equal, err := between(true, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return filters.And(open, filters.Not(equal)), nil
+}
+
+// parseDate reads the date in the first layout that fits as the first and the last instant it covers,
+// a day covers [day, day+24h) so it is equal to every bid of that day
+func parseDate(value string) (first, last time.Time, err error) {
+	for _, layout := range dateLayouts {
+		if first, err = time.Parse(layout, value); err != nil {
+			continue
+		}
+
+		if layout == dayLayout {
+			return first, first.Add(24*time.Hour - time.Nanosecond), nil
+		}
+
+		return first, first, nil
+	}
+
+	return time.Time{}, time.Time{}, err
+}
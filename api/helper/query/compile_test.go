@@ -0,0 +1,87 @@
+package query_test
+
+import (
+	"maga-auctions/api/helper/query"
+	"maga-auctions/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var vehicles = []entity.Vehicle{
+	{ID: 1, Brand: "FIAT", Model: "UNO MILLE", ManufacturingYear: 2016, ModelYear: 2016, Lot: entity.Lot{ID: "0161"}, Bid: entity.Bid{Date: time.Date(2020, 8, 22, 9, 48, 0, 0, time.UTC), Value: entity.NewMoney(450000, ""), User: "Sorico1"}},
+	{ID: 2, Brand: "FIAT", Model: "PALIO", ManufacturingYear: 2012, ModelYear: 2013, Lot: entity.Lot{ID: "0161"}, ReserveMet: true},
+	{ID: 3, Brand: "HONDA", Model: "CIVIC SEDAN LXR", ManufacturingYear: 2014, ModelYear: 2015, Lot: entity.Lot{ID: "0033"}, Bid: entity.Bid{Date: time.Date(2020, 8, 21, 12, 58, 0, 0, time.UTC), Value: entity.NewMoney(550000, ""), User: " Michaelnf "}},
+	{ID: 4, Brand: "RENAULT", Model: "UNO", ManufacturingYear: 2010, ModelYear: 2010, Lot: entity.Lot{ID: "0033"}, Bid: entity.Bid{User: "Michaelnf"}},
+}
+
+func TestFilter(t *testing.T) {
+	testCases := []struct {
+		desc, q string
+		want    []int
+	}{
+		{desc: "must filter by a text", q: "brand:fiat", want: []int{1, 2}},
+		{desc: "must filter by a pattern", q: "model:UNO*", want: []int{1, 4}},
+		{desc: "must filter by a quoted pattern", q: `model:"* SEDAN *"`, want: []int{3}},
+		{desc: "must filter by a comparison", q: "manufacturingYear>=2014", want: []int{1, 3}},
+		{desc: "must filter by a flag", q: "reserveMet:true", want: []int{2}},
+		{desc: "must join the filters", q: "brand:HONDA OR (model:UNO* AND manufacturingYear>=2015)", want: []int{1, 3}},
+		{desc: "must negate the filters", q: "NOT brand:FIAT AND lot.id:0033 AND NOT bid.user:michaelnf", want: []int{4}},
+		{desc: "must filter by the bidder like the bidder filter", q: "bid.user:MICHAELNF", want: []int{3}},
+		{desc: "must filter by a bidder pattern only the placed bids", q: "bid.user:*", want: []int{1, 3}},
+		{desc: "must filter by the bid value", q: "bid.value>=4500", want: []int{1, 3}},
+		{desc: "must filter by a strict bid value", q: "bid.value>4500.00", want: []int{3}},
+		{desc: "must filter by an exact bid value", q: "bid.value=5500", want: []int{3}},
+		{desc: "must filter by the bid date", q: `bid.date<"2020-08-22T09:48:00Z"`, want: []int{3}},
+		{desc: "must filter by the bid day", q: "bid.date>=2020-08-22", want: []int{1}},
+		{desc: "must filter by the whole bid day", q: "bid.date:2020-08-22", want: []int{1}},
+		{desc: "must filter up to the end of the bid day", q: "bid.date<=2020-08-21", want: []int{3}},
+		{desc: "must filter after the end of the bid day", q: "bid.date>2020-08-21", want: []int{1}},
+		{desc: "must filter before the start of the bid day", q: "bid.date<2020-08-22", want: []int{3}},
+		{desc: "must filter by whether there are bids", q: "hasBids:false", want: []int{2, 4}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			f, err := query.Filter(tt.q)
+			assert.Nil(t, err)
+
+			items := append([]entity.Vehicle{}, vehicles...)
+			f.Apply(&items)
+
+			ids := []int{}
+			for _, v := range items {
+				ids = append(ids, v.ID)
+			}
+
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestFilter_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, q, want string
+		position      int
+	}{
+		{desc: "must return error when the field is unknown", q: "brand:FIAT OR color:RED", want: `unknown field "color" at position 15`, position: 15},
+		{desc: "must return error when a text is compared", q: "brand>FIAT", want: `brand cannot be compared with ">" at position 6`, position: 6},
+		{desc: "must return error when the number is invalid", q: "modelYear >= 20x5", want: "modelYear needs a number at position 14", position: 14},
+		{desc: "must return error when the flag is invalid", q: "reserveMet:maybe", want: "reserveMet needs true or false at position 12", position: 12},
+		{desc: "must return error when the amount is invalid", q: "bid.value>-1", want: "bid.value needs an amount at position 11", position: 11},
+		{desc: "must return error when the date is invalid", q: "bid.date>=yesterday", want: `bid.date needs a date like 2020-08-22 or "2020-08-22T09:48:00Z" at position 11`, position: 11},
+		{desc: "must return error when a flag is compared", q: "hasBids>true", want: `hasBids cannot be compared with ">" at position 8`, position: 8},
+		{desc: "must return the syntax errors", q: "brand:FIAT OR", want: "expected a field, found end of expression at position 14", position: 14},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			f, err := query.Filter(tt.q)
+
+			assert.Nil(t, f)
+			assert.EqualError(t, err, tt.want)
+			assert.Equal(t, tt.position, err.(*query.SyntaxError).Position)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type kind int
+
+const (
+	tokenEnd kind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenOpen
+	tokenClose
+)
+
+// token is a piece of the expression, pos is the 1-based position of its first letter
+type token struct {
+	kind kind
+	text string
+	pos  int
+}
+
+// String describes the token in the errors
+func (t token) String() string {
+	if t.kind == tokenEnd {
+		return "end of expression"
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+// keyword tells whether the token is the word AND, OR or NOT in any case
+func (t token) keyword(word string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, word)
+}
+
+// special are the letters that end a word
+const special = `()":<>=`
+
+// lex splits the expression into tokens, the last one is always tokenEnd
+func lex(q string) ([]token, error) {
+	rs := []rune(q)
+	tokens := []token{}
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: pos})
+			i++
+		case r == ':' || r == '=':
+			tokens = append(tokens, token{kind: tokenOp, text: string(r), pos: pos})
+			i++
+		case r == '<' || r == '>':
+			op := string(r)
+			i++
+
+			if i < len(rs) && rs[i] == '=' {
+				op += "="
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: pos})
+		case r == '"':
+			var b strings.Builder
+			i++
+
+			for ; i < len(rs) && rs[i] != '"'; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+				}
+
+				b.WriteRune(rs[i])
+			}
+
+			if i == len(rs) {
+				return nil, &SyntaxError{Position: pos, Message: "unterminated string"}
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: pos})
+			i++
+		default:
+			start := i
+
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && !strings.ContainsRune(special, rs[i]) {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokenWord, text: string(rs[start:i]), pos: pos})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, pos: len(rs) + 1}), nil
+}
@@ -0,0 +1,173 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDepth bounds how deep the parentheses and the NOTs can nest
+const maxDepth = 32
+
+// SyntaxError tells where the expression stopped making sense
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+type parser struct {
+	tokens []token
+	i      int
+	depth  int
+}
+
+// Parse reads an expression like brand:FIAT OR (model:UNO* AND manufacturingYear>=2015)
+// NOT binds tighter than AND, and AND tighter than OR, the keywords ignore the case
+func Parse(q string) (Node, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, &SyntaxError{Position: 1, Message: "expression is empty"}
+	}
+
+	tokens, err := lex(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.or()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, p.unexpected(t, "AND or OR")
+	}
+
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+
+	if t.kind != tokenEnd {
+		p.i++
+	}
+
+	return t
+}
+
+func (p *parser) unexpected(t token, want string) error {
+	return &SyntaxError{Position: t.pos, Message: fmt.Sprintf("expected %s, found %s", want, t)}
+}
+
+func (p *parser) or() (Node, error) {
+	left, err := p.and()
+
+	for err == nil && p.peek().keyword("OR") {
+		op := p.next()
+
+		var right Node
+		right, err = p.and()
+		left = &Binary{Op: "OR", Left: left, Right: right, pos: op.pos}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return left, nil
+}
+
+func (p *parser) and() (Node, error) {
+	left, err := p.unary()
+
+	for err == nil && p.peek().keyword("AND") {
+		op := p.next()
+
+		var right Node
+		right, err = p.unary()
+		left = &Binary{Op: "AND", Left: left, Right: right, pos: op.pos}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return left, nil
+}
+
+func (p *parser) unary() (Node, error) {
+	t := p.peek()
+
+	if !t.keyword("NOT") && t.kind != tokenOpen {
+		return p.term()
+	}
+
+	if p.depth++; p.depth > maxDepth {
+		return nil, &SyntaxError{Position: t.pos, Message: "expression is nested too deep"}
+	}
+
+	defer func() { p.depth-- }()
+
+	p.next()
+
+	if t.kind == tokenWord {
+		expr, err := p.unary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &Not{Expr: expr, pos: t.pos}, nil
+	}
+
+	expr, err := p.or()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c := p.next(); c.kind != tokenClose {
+		return nil, p.unexpected(c, `")"`)
+	}
+
+	return expr, nil
+}
+
+func (p *parser) term() (Node, error) {
+	field := p.next()
+
+	if field.kind != tokenWord || field.keyword("AND") || field.keyword("OR") {
+		return nil, p.unexpected(field, "a field")
+	}
+
+	op := p.next()
+
+	if op.kind != tokenOp {
+		return nil, p.unexpected(op, "an operator")
+	}
+
+	value := p.next()
+
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, p.unexpected(value, "a value")
+	}
+
+	return &Term{
+		Field:  field.text,
+		Op:     op.text,
+		Value:  value.text,
+		pos:    field.pos,
+		opPos:  op.pos,
+		valPos: value.pos,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package query_test
+
+import (
+	"errors"
+	"maga-auctions/api/helper/query"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, q := range []string{
+		"brand:FIAT OR (model:UNO* AND manufacturingYear>=2015)",
+		`NOT model:"CIVIC SEDAN \"LXR\"" and id<10`,
+		"((brand:FIAT)",
+		`bid.user:"Damião A. d. S."`,
+		"",
+	} {
+		f.Add(q)
+	}
+
+	f.Fuzz(func(t *testing.T, q string) {
+		node, err := query.Parse(q)
+
+		if err != nil {
+			var syntax *query.SyntaxError
+
+			if !errors.As(err, &syntax) {
+				t.Fatalf("%q: error is not a syntax error: %v", q, err)
+			}
+
+			if syntax.Position < 1 || syntax.Position > utf8.RuneCountInString(q)+1 {
+				t.Fatalf("%q: position %d is out of the expression", q, syntax.Position)
+			}
+
+			return
+		}
+
+		again, err := query.Parse(node.String())
+
+		if err != nil {
+			t.Fatalf("%q: cannot read back %q: %v", q, node.String(), err)
+		}
+
+		if again.String() != node.String() {
+			t.Fatalf("%q: read back %q as %q", q, node.String(), again.String())
+		}
+
+		_, _ = query.Compile(node)
+	})
+}
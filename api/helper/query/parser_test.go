@@ -0,0 +1,60 @@
+package query_test
+
+import (
+	"maga-auctions/api/helper/query"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		desc, q, want string
+	}{
+		{desc: "must read a term", q: "brand:FIAT", want: "brand:FIAT"},
+		{desc: "must read the comparisons", q: "modelYear >= 2015", want: "modelYear>=2015"},
+		{desc: "must read a quoted value", q: `model:"CIVIC SEDAN \"LXR\""`, want: `model:"CIVIC SEDAN \"LXR\""`},
+		{desc: "must bind AND tighter than OR", q: "brand:FIAT OR model:UNO* AND manufacturingYear>=2015", want: "brand:FIAT OR (model:UNO* AND manufacturingYear>=2015)"},
+		{desc: "must follow the parentheses", q: "(brand:FIAT OR brand:HONDA) AND modelYear<2015", want: "(brand:FIAT OR brand:HONDA) AND modelYear<2015"},
+		{desc: "must bind NOT tighter than AND", q: "not brand:FIAT and NOT (model:UNO or model:PALIO)", want: "NOT brand:FIAT AND NOT (model:UNO OR model:PALIO)"},
+		{desc: "must join from the left", q: "id=1 OR id=2 OR id=3", want: "id=1 OR id=2 OR id=3"},
+		{desc: "must read a keyword as a value", q: "bid.user:and", want: "bid.user:and"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			node, err := query.Parse(tt.q)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, node.String())
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, q, want string
+		position      int
+	}{
+		{desc: "must return error when the expression is empty", q: "  ", want: "expression is empty at position 1", position: 1},
+		{desc: "must return error when the operator is missing", q: "brand FIAT", want: `expected an operator, found "FIAT" at position 7`, position: 7},
+		{desc: "must return error when the value is missing", q: "brand:", want: "expected a value, found end of expression at position 7", position: 7},
+		{desc: "must return error when the field is missing", q: "brand:FIAT AND OR model:UNO", want: `expected a field, found "OR" at position 16`, position: 16},
+		{desc: "must return error when a parenthesis is not closed", q: "(brand:FIAT OR model:UNO", want: `expected ")", found end of expression at position 25`, position: 25},
+		{desc: "must return error when a parenthesis is not opened", q: "brand:FIAT)", want: `expected AND or OR, found ")" at position 11`, position: 11},
+		{desc: "must return error when the terms are not joined", q: "brand:FIAT model:UNO", want: `expected AND or OR, found "model" at position 12`, position: 12},
+		{desc: "must return error when a string is not closed", q: `model:"UNO`, want: "unterminated string at position 7", position: 7},
+		{desc: "must count the letters and not the bytes", q: "bid.user:Damião )", want: `expected AND or OR, found ")" at position 17`, position: 17},
+		{desc: "must return error when the expression is nested too deep", q: "NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT NOT id=1", want: "expression is nested too deep at position 129", position: 129},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			node, err := query.Parse(tt.q)
+
+			assert.Nil(t, node)
+			assert.EqualError(t, err, tt.want)
+			assert.Equal(t, tt.position, err.(*query.SyntaxError).Position)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package sorting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Compare orders two items by one field, it is negative when a comes first
+type Compare func(a, b interface{}) int
+
+// Fields are the fields a list can be sorted by
+type Fields map[string]Compare
+
+// Comparator orders two items by every key of a sort
+type Comparator func(a, b interface{}) int
+
+type key struct {
+	compare Compare
+	desc    bool
+}
+
+// Build reads a sort like -bid.value,brand, a leading - orders the field descending and the next keys break the ties
+func (f Fields) Build(sort string) (Comparator, error) {
+	keys := []key{}
+
+	if strings.TrimSpace(sort) == "" {
+		return func(a, b interface{}) int { return 0 }, nil
+	}
+
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		name := strings.TrimPrefix(field, "-")
+		compare, ok := f[name]
+
+		if !ok {
+			return nil, fmt.Errorf("cannot be sorted by %q", name)
+		}
+
+		keys = append(keys, key{compare: compare, desc: name != field})
+	}
+
+	return func(a, b interface{}) int {
+		for _, k := range keys {
+			c := k.compare(a, b)
+
+			if k.desc {
+				c = -c
+			}
+
+			if c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	}, nil
+}
+
+// Less tells whether a comes before b, for sort.SliceStable
+func (c Comparator) Less(a, b interface{}) bool {
+	return c(a, b) < 0
+}
+
+// Ints orders the numbers
+func Ints(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// Strings orders the texts ignoring the case
+func Strings(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// Times orders the dates
+func Times(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+
+	return 0
+}
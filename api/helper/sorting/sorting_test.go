@@ -0,0 +1,80 @@
+package sorting_test
+
+import (
+	"maga-auctions/api/helper/sorting"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	name  string
+	year  int
+	since time.Time
+}
+
+var fields = sorting.Fields{
+	"name":  func(a, b interface{}) int { return sorting.Strings(a.(item).name, b.(item).name) },
+	"year":  func(a, b interface{}) int { return sorting.Ints(a.(item).year, b.(item).year) },
+	"since": func(a, b interface{}) int { return sorting.Times(a.(item).since, b.(item).since) },
+}
+
+func TestBuild(t *testing.T) {
+	day := time.Date(2020, 8, 21, 0, 0, 0, 0, time.UTC)
+	items := []item{
+		{name: "fiat", year: 2010, since: day},
+		{name: "Honda", year: 2015, since: day.Add(time.Hour)},
+		{name: "honda", year: 2014, since: day},
+		{name: "FIAT", year: 2010, since: day.Add(time.Hour)},
+	}
+
+	testCases := []struct {
+		desc, sort string
+		want       []int
+	}{
+		{desc: "must keep the order without a sort", sort: "", want: []int{2010, 2015, 2014, 2010}},
+		{desc: "must order by one field", sort: "year", want: []int{2010, 2010, 2014, 2015}},
+		{desc: "must order descending", sort: "-year", want: []int{2015, 2014, 2010, 2010}},
+		{desc: "must break the ties with the next fields", sort: "-name,year", want: []int{2014, 2015, 2010, 2010}},
+		{desc: "must keep the order of the ties", sort: "name", want: []int{2010, 2010, 2015, 2014}},
+		{desc: "must ignore the spaces", sort: " -since , -year", want: []int{2015, 2010, 2014, 2010}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			cmp, err := fields.Build(tt.sort)
+			assert.Nil(t, err)
+
+			got := append([]item{}, items...)
+			sort.SliceStable(got, func(i, j int) bool { return cmp.Less(got[i], got[j]) })
+
+			years := []int{}
+			for _, it := range got {
+				years = append(years, it.year)
+			}
+
+			assert.Equal(t, tt.want, years)
+		})
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, sort, want string
+	}{
+		{desc: "must return error when the field is unknown", sort: "name,color", want: `cannot be sorted by "color"`},
+		{desc: "must return error when a field is blank", sort: "name,,year", want: `cannot be sorted by ""`},
+		{desc: "must return error when the field is only a minus", sort: "-", want: `cannot be sorted by ""`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			cmp, err := fields.Build(tt.sort)
+
+			assert.Nil(t, cmp)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
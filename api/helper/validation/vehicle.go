@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"maga-auctions/api/handler"
+	"maga-auctions/entity"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// now is the clock of the rules about the years
+var now = time.Now
+
+var validate = newValidator()
+
+// newValidator reads the validate tags of the entities and names the fields as in the json
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	_ = v.RegisterValidation("maxyear", maxYear)
+
+	return v
+}
+
+// maxYear accepts years up to the current one plus the param
+func maxYear(fl validator.FieldLevel) bool {
+	return int(fl.Field().Int()) <= lastYear(fl.Param())
+}
+
+// lastYear is the current year plus the years ahead
+func lastYear(ahead string) int {
+	n, _ := strconv.Atoi(ahead)
+
+	return now().Year() + n
+}
+
+// Vehicle checks the vehicle against the rules declared on the entities and its reserve price, the error lists every broken rule
+func Vehicle(v entity.Vehicle) error {
+	var errs validator.ValidationErrors
+
+	if err := validate.Struct(v); err != nil && !errors.As(err, &errs) {
+		return handler.InternalServer{Message: err.Error()}
+	}
+
+	fields := make([]handler.FieldError, 0, len(errs)+1)
+
+	for _, e := range errs {
+		field := e.Namespace()
+
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		fields = append(fields, handler.FieldError{
+			Field:   field,
+			Rule:    e.Tag(),
+			Message: message(field, e),
+		})
+	}
+
+	// the reserve is kept out of the json of the entity, so it has no tag to validate
+	if v.Reserve != nil && v.Reserve.IsNegative() {
+		fields = append(fields, handler.FieldError{Field: "reservePrice", Rule: "gte", Message: "reservePrice must be at least 0"})
+	}
+
+	// the reserve is compared with the bids, which the legacy api keeps in the default currency only
+	if v.Reserve != nil && v.Reserve.CurrencyCode() != entity.DefaultCurrency {
+		fields = append(fields, handler.FieldError{Field: "reservePrice", Rule: "currency", Message: "reservePrice must be in " + entity.DefaultCurrency})
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return handler.UnprocessableEntity{Message: "vehicle is invalid", Fields: fields}
+}
+
+// message describes the broken rule
+func message(field string, e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s", field, e.Param())
+	case "gtefield":
+		return fmt.Sprintf("%s cannot be before %s", field, lowerFirst(e.Param()))
+	case "maxyear":
+		return fmt.Sprintf("%s cannot be after %d", field, lastYear(e.Param()))
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// lowerFirst turns a struct field name into its json name
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,106 @@
+package validation_test
+
+import (
+	"maga-auctions/api/handler"
+	"maga-auctions/api/helper/validation"
+	"maga-auctions/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func valid() entity.Vehicle {
+	return entity.Vehicle{
+		Brand:             "RENAULT",
+		Model:             "CLIO 16VS",
+		ModelYear:         2008,
+		ManufacturingYear: 2007,
+		Lot:               entity.Lot{ID: "0196", VehicleLotID: "56248"},
+		Bid:               entity.Bid{Date: time.Date(2020, 8, 27, 10, 20, 0, 0, time.UTC), Value: entity.NewMoney(1500000, ""), User: "ALLBARBOS"},
+	}
+}
+
+func TestVehicle(t *testing.T) {
+	t.Run("must accept a valid vehicle", func(t *testing.T) {
+		assert.Nil(t, validation.Vehicle(valid()))
+	})
+
+	t.Run("must leave the bid to the bid endpoint", func(t *testing.T) {
+		ve := valid()
+		ve.Bid = entity.Bid{Date: time.Now().Add(time.Hour), Value: entity.NewMoney(-1, "")}
+
+		assert.Nil(t, validation.Vehicle(ve))
+	})
+}
+
+func TestVehicle_Errors(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		change func(ve *entity.Vehicle)
+		want   []handler.FieldError
+	}{
+		{
+			desc:   "must return error when brand is empty",
+			change: func(ve *entity.Vehicle) { ve.Brand = "" },
+			want:   []handler.FieldError{{Field: "brand", Rule: "required", Message: "brand is required"}},
+		},
+		{
+			desc:   "must return error when model year is before the manufacturing year",
+			change: func(ve *entity.Vehicle) { ve.ModelYear = 2006 },
+			want:   []handler.FieldError{{Field: "modelYear", Rule: "gtefield", Message: "modelYear cannot be before manufacturingYear"}},
+		},
+		{
+			desc:   "must return error when years are in the future",
+			change: func(ve *entity.Vehicle) { ve.ModelYear, ve.ManufacturingYear = 3000, 3000 },
+			want: []handler.FieldError{
+				{Field: "modelYear", Rule: "maxyear", Message: "modelYear cannot be after " + year(1)},
+				{Field: "manufacturingYear", Rule: "maxyear", Message: "manufacturingYear cannot be after " + year(0)},
+			},
+		},
+		{
+			desc:   "must return error when the manufacturing year is too old",
+			change: func(ve *entity.Vehicle) { ve.ModelYear, ve.ManufacturingYear = 1800, 1800 },
+			want:   []handler.FieldError{{Field: "manufacturingYear", Rule: "min", Message: "manufacturingYear must be at least 1900"}},
+		},
+		{
+			desc:   "must return error when lot is missing",
+			change: func(ve *entity.Vehicle) { ve.Lot = entity.Lot{} },
+			want: []handler.FieldError{
+				{Field: "lot.id", Rule: "required", Message: "lot.id is required"},
+				{Field: "lot.vehicleLotId", Rule: "required", Message: "lot.vehicleLotId is required"},
+			},
+		},
+		{
+			desc: "must return error when the reserve price is negative",
+			change: func(ve *entity.Vehicle) {
+				negative := entity.NewMoney(-1, "")
+				ve.Reserve = &negative
+			},
+			want: []handler.FieldError{{Field: "reservePrice", Rule: "gte", Message: "reservePrice must be at least 0"}},
+		},
+		{
+			desc: "must return error when the reserve price is in another currency",
+			change: func(ve *entity.Vehicle) {
+				usd := entity.NewMoney(100, "USD")
+				ve.Reserve = &usd
+			},
+			want: []handler.FieldError{{Field: "reservePrice", Rule: "currency", Message: "reservePrice must be in BRL"}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ve := valid()
+			tt.change(&ve)
+
+			err := validation.Vehicle(ve)
+
+			assert.Equal(t, handler.UnprocessableEntity{Message: "vehicle is invalid", Fields: tt.want}, err)
+		})
+	}
+}
+
+func year(ahead int) string {
+	return time.Date(time.Now().Year()+ahead, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006")
+}
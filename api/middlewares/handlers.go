@@ -8,3 +8,18 @@ func NoRouteHandler() gin.HandlerFunc {
 		c.JSON(404, gin.H{"message": "The processing function of the request route was not found"})
 	}
 }
+
+// Segment serves the route only when the param is the literal, gin cannot mix a static segment with a param in the same position
+func Segment(param, literal string) gin.HandlerFunc {
+	notFound := NoRouteHandler()
+
+	return func(c *gin.Context) {
+		if c.Param(param) != literal {
+			notFound(c)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
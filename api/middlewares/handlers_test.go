@@ -0,0 +1,40 @@
+package middlewares_test
+
+import (
+	"maga-auctions/api/middlewares"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegment(t *testing.T) {
+	ok := func(c *gin.Context) { c.Status(200) }
+
+	app := gin.New()
+	app.POST("/vehicles/:id", middlewares.Segment("id", "validate"), ok)
+	app.POST("/vehicles/:id/bids", ok)
+	app.POST("/vehicles/:id/max-bids", ok)
+
+	testCases := []struct {
+		desc, path string
+		want       int
+	}{
+		{desc: "must serve the literal segment", path: "/vehicles/validate", want: 200},
+		{desc: "must not serve another value of the param", path: "/vehicles/760", want: 404},
+		{desc: "must keep serving the routes below the param", path: "/vehicles/760/bids", want: 200},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, nil)
+
+			app.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
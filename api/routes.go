@@ -1,10 +1,15 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"log"
 	ctrl "maga-auctions/api/controller"
+	"maga-auctions/api/helper/paging"
 	"maga-auctions/api/middlewares"
+	"maga-auctions/entity"
 	"maga-auctions/legacy"
+	"maga-auctions/lot"
 	"maga-auctions/utils"
 	"maga-auctions/vehicle"
 
@@ -35,32 +40,116 @@ func Config() *gin.Engine {
 	app.Use(middlewares.CORS())
 	app.NoRoute(middlewares.NoRouteHandler())
 
-	app.GET("/maga-auctions/v1/health-check", healthCtrl().HealthCheck)
+	pageSizes()
+	invalidBidDate()
+	api, breaker, cache := buildAPI()
+	auctions := lot.NewService(
+		lot.WithClosingWindow(utils.EnvVars.Auction.ClosingWindow),
+		lot.WithSoftClose(utils.EnvVars.Auction.Extension, utils.EnvVars.Auction.MaxExtension),
+	)
+	srv := vehicle.NewService(api, vehicle.WithMinIncrement(minIncrement()), vehicle.WithLots(auctions))
+	health := ctrl.NewHealthCheck(srv, breaker, cache)
+	vehicles := ctrl.NewVehicle(srv)
+	lots := ctrl.NewLot(srv, auctions)
 
-	app.POST("/maga-auctions/v1/vehicles", vehicleCtrl().Create)
-	app.GET("/maga-auctions/v1/vehicles", vehicleCtrl().All)
-	app.GET("/maga-auctions/v1/vehicles/:id", vehicleCtrl().ByID)
-	app.PUT("/maga-auctions/v1/vehicles/:id", vehicleCtrl().Update)
-	app.DELETE("/maga-auctions/v1/vehicles/:id", vehicleCtrl().Delete)
+	if every := utils.EnvVars.Auction.SchedulerInterval; every > 0 {
+		go auctions.Run(context.Background(), every)
+	}
+
+	app.GET("/maga-auctions/v1/health-check", health.HealthCheck)
+
+	app.POST("/maga-auctions/v1/vehicles", vehicles.Create)
+	app.POST("/maga-auctions/v1/vehicles/:id", middlewares.Segment("id", "validate"), vehicles.Validate)
+	app.GET("/maga-auctions/v1/vehicles", vehicles.All)
+	app.GET("/maga-auctions/v1/vehicles/:id", vehicles.ByID)
+	app.PUT("/maga-auctions/v1/vehicles/:id", vehicles.Update)
+	app.DELETE("/maga-auctions/v1/vehicles/:id", vehicles.Delete)
+	app.GET("/maga-auctions/v1/vehicles/:id/bids", vehicles.Bids)
+	app.POST("/maga-auctions/v1/vehicles/:id/bids", vehicles.PlaceBid)
+	app.POST("/maga-auctions/v1/vehicles/:id/max-bids", vehicles.SetMaxBid)
 
-	app.GET("/maga-auctions/v1/lots/:id/vehicles", lotCtrl().VehiclesByLot)
+	app.GET("/maga-auctions/v1/users/:user/bids", vehicles.BidsByUser)
+
+	app.GET("/maga-auctions/v1/lots", lots.All)
+	app.GET("/maga-auctions/v1/lots/:id", lots.ByID)
+	app.PUT("/maga-auctions/v1/lots/:id/schedule", lots.Schedule)
+	app.POST("/maga-auctions/v1/lots/:id/close", lots.Close)
+	app.GET("/maga-auctions/v1/lots/:id/results", lots.Results)
+	app.GET("/maga-auctions/v1/lots/:id/vehicles", lots.VehiclesByLot)
+	app.GET("/maga-auctions/v1/lots/:id/vehicles/:vehicleLotId", lots.VehicleByLotID)
 
 	return app
 }
 
-func buildSrv() vehicle.Service {
-	api := legacy.NewAPI()
-	return vehicle.NewService(api)
+// minIncrement reads the configured amount a bid must beat the current one by
+func minIncrement() entity.Money {
+	cfg := utils.EnvVars.Auction.MinIncrement
+
+	if cfg == "" {
+		return entity.Money{}
+	}
+
+	m, err := entity.ParseMoney(cfg, entity.DefaultCurrency)
+
+	if err != nil || m.IsNegative() {
+		log.Fatalf("auction min increment %q is invalid", cfg)
+	}
+
+	return m
 }
 
-func healthCtrl() ctrl.HealthCheck {
-	return ctrl.NewHealthCheck(buildSrv())
+// pageSizes sets the configured default and max size of the pages
+func pageSizes() {
+	cfg := utils.EnvVars.API
+
+	if err := paging.SetSizes(cfg.PageSize, cfg.MaxPageSize); err != nil {
+		log.Fatalf("api %s", err)
+	}
 }
 
-func vehicleCtrl() ctrl.VehicleController {
-	return ctrl.NewVehicle(buildSrv())
+// invalidBidDate checks the configured policy for the legacy rows whose bid date cannot be parsed
+func invalidBidDate() {
+	switch legacy.InvalidBidDate {
+	case legacy.SkipInvalidDate, legacy.ZeroInvalidDate, legacy.FailInvalidDate:
+	default:
+		log.Fatalf("legacy invalid bid date policy %q is invalid", legacy.InvalidBidDate)
+	}
 }
 
-func lotCtrl() ctrl.LotController {
-	return ctrl.NewLot(buildSrv())
+// buildAPI decorates the legacy api as configured, the breaker and the cache are nil when disabled
+func buildAPI() (legacy.API, legacy.Breaker, legacy.Cache) {
+	api := legacy.NewAPI()
+	cfg := utils.EnvVars.Legacy
+
+	if cfg.Retry.MaxAttempts > 1 {
+		api = legacy.NewRetry(api, legacy.RetryPolicy{
+			MaxAttempts:      cfg.Retry.MaxAttempts,
+			Backoff:          cfg.Retry.Backoff,
+			MaxBackoff:       cfg.Retry.MaxBackoff,
+			Jitter:           cfg.Retry.Jitter,
+			RetryableStatus:  cfg.Retry.Status,
+			IdempotentCreate: cfg.Retry.IdempotentCreate,
+		})
+	}
+
+	var breaker legacy.Breaker
+
+	if cfg.Breaker.FailureThreshold > 0 {
+		breaker = legacy.NewBreaker(api, legacy.BreakerPolicy{
+			FailureThreshold: cfg.Breaker.FailureThreshold,
+			SuccessThreshold: cfg.Breaker.SuccessThreshold,
+			CoolDown:         cfg.Breaker.CoolDown,
+		})
+		api = breaker
+	}
+
+	// cache → breaker → retry → api, a hit never reaches the breaker and a miss still gets its snapshot
+	var cache legacy.Cache
+
+	if cfg.Cache.TTL > 0 {
+		cache = legacy.NewCache(api, cfg.Cache.TTL)
+		api = cache
+	}
+
+	return api, breaker, cache
 }
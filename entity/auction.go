@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// Auction states of a lot
+const (
+	AuctionScheduled = "scheduled" // before the start
+	AuctionOpen      = "open"      // taking bids
+	AuctionClosing   = "closing"   // taking the last bids before the end
+	AuctionClosed    = "closed"    // ended, waiting for the settlement
+	AuctionSettled   = "settled"   // the results are final
+)
+
+// Auction of a lot, the legacy api knows only the lot id so the schedule is kept here
+type Auction struct {
+	LotID          string    `json:"id"`
+	StartsAt       time.Time `json:"startsAt"`
+	EndsAt         time.Time `json:"endsAt"`         // closing time, late bids push it out
+	ScheduledEndAt time.Time `json:"scheduledEndAt"` // closing time set by the schedule
+	SettledAt      time.Time `json:"settledAt"`
+}
+
+// Scheduled reports whether the auction has a schedule, lots without one are always open
+func (a Auction) Scheduled() bool {
+	return !a.EndsAt.IsZero()
+}
+
+// StatusAt returns the state of the auction at now, it is closing during the window before the end
+func (a Auction) StatusAt(now time.Time, closingWindow time.Duration) string {
+	switch {
+	case !a.SettledAt.IsZero():
+		return AuctionSettled
+	case !a.Scheduled():
+		return AuctionOpen
+	case now.Before(a.StartsAt):
+		return AuctionScheduled
+	case !now.Before(a.EndsAt):
+		return AuctionClosed
+	case !now.Before(a.EndsAt.Add(-closingWindow)):
+		return AuctionClosing
+	default:
+		return AuctionOpen
+	}
+}
+
+// Extend pushes the end out by extension when now is within window of it, up to max past the scheduled end.
+// It is false when the end did not move.
+func (a *Auction) Extend(now time.Time, window, extension, max time.Duration) bool {
+	if !a.Scheduled() || extension <= 0 || now.Before(a.EndsAt.Add(-window)) || !now.Before(a.EndsAt) {
+		return false
+	}
+
+	end := a.EndsAt.Add(extension)
+	limit := a.ScheduledEndAt.Add(max)
+
+	if end.After(limit) {
+		end = limit
+	}
+
+	if !end.After(a.EndsAt) {
+		return false
+	}
+
+	a.EndsAt = end
+
+	return true
+}
+
+// TakesBids reports whether the state accepts bids
+func TakesBids(status string) bool {
+	return status == AuctionOpen || status == AuctionClosing
+}
@@ -0,0 +1,78 @@
+package entity_test
+
+import (
+	"maga-auctions/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuction_StatusAt(t *testing.T) {
+	start := time.Date(2020, 8, 28, 9, 0, 0, 0, time.UTC)
+	a := entity.Auction{LotID: "0196", StartsAt: start, EndsAt: start.Add(time.Hour)}
+	settled := a
+	settled.SettledAt = start.Add(2 * time.Hour)
+
+	testCases := []struct {
+		desc, want string
+		auction    entity.Auction
+		now        time.Time
+	}{
+		{desc: "must be open without a schedule", auction: entity.Auction{LotID: "0196"}, now: start, want: entity.AuctionOpen},
+		{desc: "must be scheduled before the start", auction: a, now: start.Add(-time.Second), want: entity.AuctionScheduled},
+		{desc: "must be open at the start", auction: a, now: start, want: entity.AuctionOpen},
+		{desc: "must be closing in the window", auction: a, now: start.Add(50 * time.Minute), want: entity.AuctionClosing},
+		{desc: "must be closed at the end", auction: a, now: start.Add(time.Hour), want: entity.AuctionClosed},
+		{desc: "must be settled once settled", auction: settled, now: start.Add(3 * time.Hour), want: entity.AuctionSettled},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.auction.StatusAt(tt.now, 10*time.Minute))
+		})
+	}
+}
+
+func TestTakesBids(t *testing.T) {
+	assert.True(t, entity.TakesBids(entity.AuctionOpen))
+	assert.True(t, entity.TakesBids(entity.AuctionClosing))
+	assert.False(t, entity.TakesBids(entity.AuctionScheduled))
+	assert.False(t, entity.TakesBids(entity.AuctionClosed))
+	assert.False(t, entity.TakesBids(entity.AuctionSettled))
+}
+
+func TestAuction_Extend(t *testing.T) {
+	start := time.Date(2020, 8, 28, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	window, extension, max := 5*time.Minute, 3*time.Minute, 5*time.Minute
+
+	testCases := []struct {
+		desc    string
+		endsAt  time.Time
+		now     time.Time
+		want    time.Time
+		extends bool
+	}{
+		{desc: "must not extend before the window", endsAt: end, now: end.Add(-window - time.Second), want: end},
+		{desc: "must extend within the window", endsAt: end, now: end.Add(-time.Minute), want: end.Add(extension), extends: true},
+		{desc: "must extend up to the cap", endsAt: end.Add(extension), now: end.Add(extension - time.Minute), want: end.Add(max), extends: true},
+		{desc: "must not extend past the cap", endsAt: end.Add(max), now: end.Add(max - time.Minute), want: end.Add(max)},
+		{desc: "must not extend after the end", endsAt: end, now: end, want: end},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			a := entity.Auction{LotID: "0196", StartsAt: start, EndsAt: tt.endsAt, ScheduledEndAt: end}
+
+			assert.Equal(t, tt.extends, a.Extend(tt.now, window, extension, max))
+			assert.Equal(t, tt.want, a.EndsAt)
+		})
+	}
+
+	t.Run("must not extend a lot without schedule", func(t *testing.T) {
+		a := entity.Auction{LotID: "0196"}
+
+		assert.False(t, a.Extend(start, window, extension, max))
+	})
+}
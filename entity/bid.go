@@ -5,6 +5,16 @@ import "time"
 // Bid entity
 type Bid struct {
 	Date  time.Time `json:"date"`  // DATALANCE - Data/hora que foi realizado o último lance
-	Value float32   `json:"value"` // VALORLANCE - Valor do último lance dado
+	Value Money     `json:"value"` // VALORLANCE - Valor do último lance dado
 	User  string    `json:"user"`  // USUARIOLANCE - Usuário cadastrado na plataforma que fez o último lance
 }
+
+// Placed reports whether the bid was given, vehicles without bids come with a zero value
+func (b Bid) Placed() bool {
+	return !b.Value.IsZero()
+}
+
+// Equal reports whether both bids are the same
+func (b Bid) Equal(other Bid) bool {
+	return b.User == other.User && b.Value.Cmp(other.Value) == 0 && b.Date.Equal(other.Date)
+}
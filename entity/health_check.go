@@ -4,4 +4,5 @@ package entity
 type HealthCheck struct {
 	Status       string            `json:"status"`
 	Dependencies map[string]string `json:"dependencies"`
+	Counters     map[string]uint64 `json:"counters"`
 }
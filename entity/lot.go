@@ -2,6 +2,6 @@ package entity
 
 // Lot entity
 type Lot struct {
-	ID           string `json:"id"`           // LOTE - Agrupador de um conjunto de veículos
-	VehicleLotID string `json:"vehicleLotId"` // CODIGOCONTROLE - Código único do veículo dentro do lote
+	ID           string `json:"id" validate:"required"`           // LOTE - Agrupador de um conjunto de veículos
+	VehicleLotID string `json:"vehicleLotId" validate:"required"` // CODIGOCONTROLE - Código único do veículo dentro do lote
 }
@@ -0,0 +1,193 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of the amounts that do not state one
+const DefaultCurrency = "BRL"
+
+// minorUnits is the number of decimal places kept by Money
+const minorUnits = 2
+
+var (
+	// ErrCurrencyMismatch is returned by the arithmetic between different currencies
+	ErrCurrencyMismatch = errors.New("money currencies do not match")
+
+	scale = big.NewRat(int64(math.Pow10(minorUnits)), 1)
+
+	// decimal is a plain decimal amount, big.Rat alone also takes base prefixes such as 0x10 and digit separators
+	decimal = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d{1,2})?$`)
+)
+
+// Money is an exact amount in minor units (cents) of an ISO 4217 currency
+type Money struct {
+	Amount   int64  // minor units
+	Currency string // ISO 4217 code, DefaultCurrency when empty
+}
+
+// NewMoney returns the amount in minor units of the currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount such as "4.30", "-10" or "1.5e3" without losing precision
+func ParseMoney(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+
+	if !decimal.MatchString(s) {
+		return Money{}, fmt.Errorf("invalid money amount %q", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+
+	if !ok {
+		return Money{}, fmt.Errorf("invalid money amount %q", s)
+	}
+
+	r.Mul(r, scale)
+
+	if !r.IsInt() {
+		return Money{}, fmt.Errorf("money amount %q has more than %d decimal places", s, minorUnits)
+	}
+
+	if !r.Num().IsInt64() {
+		return Money{}, fmt.Errorf("money amount %q is out of range", s)
+	}
+
+	return Money{Amount: r.Num().Int64(), Currency: currency}, nil
+}
+
+// CurrencyCode returns the ISO 4217 code of the amount
+func (m Money) CurrencyCode() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+
+	return m.Currency
+}
+
+// String formats the amount with all its decimal places, such as "4.30"
+func (m Money) String() string {
+	sign := ""
+	amount := m.Amount
+
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	unit := int64(math.Pow10(minorUnits))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, minorUnits, amount%unit)
+}
+
+// moneyJSON is the json of the amounts in a currency other than the default one
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON writes the amount as an exact json number, an amount in another currency than the default one goes with its code
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount := []byte(strings.TrimSuffix(m.String(), "."+strings.Repeat("0", minorUnits)))
+
+	if m.CurrencyCode() == DefaultCurrency {
+		return amount, nil
+	}
+
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON reads the amount from a json number or string, or from an object with the amount and the currency
+func (m *Money) UnmarshalJSON(b []byte) error {
+	s := string(b)
+
+	if s == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		var obj moneyJSON
+
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return err
+		}
+
+		m.Currency = strings.ToUpper(strings.TrimSpace(obj.Currency))
+		s = string(obj.Amount)
+	}
+
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := ParseMoney(s, m.Currency)
+
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Add returns the sum of both amounts
+func (m Money) Add(o Money) (Money, error) {
+	if m.CurrencyCode() != o.CurrencyCode() {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference between both amounts
+func (m Money) Sub(o Money) (Money, error) {
+	if m.CurrencyCode() != o.CurrencyCode() {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Compare returns -1, 0 or +1 comparing the amounts, the amounts of different currencies cannot be compared
+func (m Money) Compare(o Money) (int, error) {
+	if m.CurrencyCode() != o.CurrencyCode() {
+		return 0, ErrCurrencyMismatch
+	}
+
+	return m.Cmp(o), nil
+}
+
+// Cmp returns -1, 0 or +1 comparing the currency codes first and then the amounts, a total order for sorting,
+// the callers that compare values use Compare to catch the different currencies
+func (m Money) Cmp(o Money) int {
+	if c := strings.Compare(m.CurrencyCode(), o.CurrencyCode()); c != 0 {
+		return c
+	}
+
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero tells whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative tells whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
@@ -0,0 +1,109 @@
+package entity_test
+
+import (
+	"encoding/json"
+	"maga-auctions/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		desc, input, wantErr string
+		want                 int64
+	}{
+		{desc: "must parse an integer", input: "5500", want: 550000},
+		{desc: "must parse decimals exactly", input: "4.3", want: 430},
+		{desc: "must parse negative amounts", input: "-0.05", want: -5},
+		{desc: "must parse exponents", input: "1.5e3", want: 150000},
+		{desc: "must reject extra decimal places", input: "4.301", wantErr: `money amount "4.301" has more than 2 decimal places`},
+		{desc: "must reject fractions", input: "1/3", wantErr: `invalid money amount "1/3"`},
+		{desc: "must reject text", input: "abc", wantErr: `invalid money amount "abc"`},
+		{desc: "must reject base prefixes", input: "0x10", wantErr: `invalid money amount "0x10"`},
+		{desc: "must reject digit separators", input: "0b1_0", wantErr: `invalid money amount "0b1_0"`},
+		{desc: "must reject huge exponents", input: "1e999999999", wantErr: `invalid money amount "1e999999999"`},
+		{desc: "must reject overflows", input: "1e30", wantErr: `money amount "1e30" is out of range`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			m, err := entity.ParseMoney(tt.input, entity.DefaultCurrency)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, entity.NewMoney(tt.want, entity.DefaultCurrency), m)
+		})
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	testCases := []struct {
+		desc, input, output string
+	}{
+		{desc: "must read numbers", input: `{"value":4.3}`, output: `{"value":4.30}`},
+		{desc: "must read strings", input: `{"value":"4.30"}`, output: `{"value":4.30}`},
+		{desc: "must write whole amounts without decimals", input: `{"value":5500}`, output: `{"value":5500}`},
+		{desc: "must write negative amounts", input: `{"value":"-0.5"}`, output: `{"value":-0.50}`},
+		{desc: "must keep the currency other than the default one", input: `{"value":{"amount":"10.5","currency":"usd"}}`, output: `{"value":{"amount":10.50,"currency":"USD"}}`},
+		{desc: "must write the default currency as a number", input: `{"value":{"amount":10,"currency":"BRL"}}`, output: `{"value":10}`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			var b entity.Bid
+
+			assert.Nil(t, json.Unmarshal([]byte(tt.input), &b))
+
+			out, err := json.Marshal(struct {
+				Value entity.Money `json:"value"`
+			}{b.Value})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.output, string(out))
+		})
+	}
+
+	t.Run("must reject invalid amounts", func(t *testing.T) {
+		var b entity.Bid
+
+		assert.NotNil(t, json.Unmarshal([]byte(`{"value":"4.301"}`), &b))
+		assert.NotNil(t, json.Unmarshal([]byte(`{"value":true}`), &b))
+	})
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	a := entity.NewMoney(430, "")
+	b := entity.NewMoney(120, entity.DefaultCurrency)
+
+	sum, err := a.Add(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "5.50", sum.String())
+
+	diff, err := b.Sub(a)
+	assert.Nil(t, err)
+	assert.Equal(t, "-3.10", diff.String())
+	assert.True(t, diff.IsNegative())
+
+	_, err = a.Add(entity.NewMoney(1, "USD"))
+	assert.Equal(t, entity.ErrCurrencyMismatch, err)
+}
+
+func TestMoney_Cmp(t *testing.T) {
+	assert.Equal(t, 1, entity.NewMoney(430, "").Cmp(entity.NewMoney(429, "BRL")))
+	assert.Equal(t, 0, entity.NewMoney(430, "").Cmp(entity.NewMoney(430, "BRL")))
+	assert.Equal(t, -1, entity.NewMoney(1, "").Cmp(entity.NewMoney(2, "")))
+	assert.Equal(t, -1, entity.NewMoney(9, "BRL").Cmp(entity.NewMoney(1, "USD")))
+
+	c, err := entity.NewMoney(430, "").Compare(entity.NewMoney(429, "BRL"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, c)
+
+	_, err = entity.NewMoney(9, "BRL").Compare(entity.NewMoney(1, "USD"))
+	assert.Equal(t, entity.ErrCurrencyMismatch, err)
+	assert.True(t, entity.Money{}.IsZero())
+}
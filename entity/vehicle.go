@@ -1,24 +1,77 @@
 package entity
 
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+)
+
 // Vehicle entity
 type Vehicle struct {
-	ID                int    `json:"id"`                // ID - Identificador único do veículo
-	Brand             string `json:"brand"`             // MARCA - Marca do veículo
-	Model             string `json:"model"`             // MODELO - Modelo do veículo
-	ModelYear         int    `json:"modelYear"`         // ANOMODELO - Ano do modelo do veículo
-	ManufacturingYear int    `json:"manufacturingYear"` // ANOFABRICACAO - Ano de fabricação do veículo
+	ID                int    `json:"id"`                                                                 // ID - Identificador único do veículo
+	Brand             string `json:"brand" validate:"required"`                                          // MARCA - Marca do veículo
+	Model             string `json:"model" validate:"required"`                                          // MODELO - Modelo do veículo
+	ModelYear         int    `json:"modelYear" validate:"required,gtefield=ManufacturingYear,maxyear=1"` // ANOMODELO - Ano do modelo do veículo
+	ManufacturingYear int    `json:"manufacturingYear" validate:"required,min=1900,maxyear=0"`           // ANOFABRICACAO - Ano de fabricação do veículo
 	Lot               Lot    `json:"lot"`
 	Bid               Bid    `json:"bid"`
+	Reserve           *Money `json:"-"`          // Preço de reserva - guardado localmente e nunca exposto, a API legada não tem o campo
+	ReserveMet        bool   `json:"reserveMet"` // Se o lance atual alcança o preço de reserva
+}
+
+// MeetsReserve tells whether the current bid reaches the reserve price, a vehicle without reserve always does
+func (v Vehicle) MeetsReserve() bool {
+	if v.Reserve == nil || v.Reserve.IsZero() {
+		return true
+	}
+
+	if !v.Bid.Placed() {
+		return false
+	}
+
+	diff, err := v.Bid.Value.Sub(*v.Reserve)
+
+	return err == nil && !diff.IsNegative()
+}
+
+// Version is a token of the vehicle content, it changes whenever any field changes
+func (v Vehicle) Version() string {
+	// reserve met is derived from the bid, it is not content of its own
+	v.ReserveMet = false
+
+	b, _ := json.Marshal(v)
+	h := fnv.New64a()
+	h.Write(b)
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// VehicleIndex provides lookups by ID over a list of vehicles
+type VehicleIndex struct {
+	items []Vehicle
+	byID  map[int]int
 }
 
-type VehiclesAsc []Vehicle
+// NewVehicleIndex indexes the vehicles by their legacy ID, the first occurrence wins
+func NewVehicleIndex(items []Vehicle) VehicleIndex {
+	byID := make(map[int]int, len(items))
 
-func (v VehiclesAsc) Len() int           { return len(v) }
-func (v VehiclesAsc) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
-func (v VehiclesAsc) Less(i, j int) bool { return v[i].Bid.Date.Before(v[j].Bid.Date) }
+	for i, v := range items {
+		if _, ok := byID[v.ID]; !ok {
+			byID[v.ID] = i
+		}
+	}
 
-type VehiclesDesc []Vehicle
+	return VehicleIndex{items: items, byID: byID}
+}
+
+// ByID returns the vehicle with the given ID
+func (x VehicleIndex) ByID(id int) (Vehicle, bool) {
+	i, ok := x.byID[id]
 
-func (v VehiclesDesc) Len() int           { return len(v) }
-func (v VehiclesDesc) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
-func (v VehiclesDesc) Less(i, j int) bool { return v[i].Bid.Date.After(v[j].Bid.Date) }
+	if !ok {
+		return Vehicle{}, false
+	}
+
+	return x.items[i], true
+}
@@ -2,7 +2,6 @@ package entity_test
 
 import (
 	"maga-auctions/entity"
-	"sort"
 	"testing"
 	"time"
 
@@ -19,7 +18,7 @@ var (
 		Bid: entity.Bid{
 			Date:  time.Now(),
 			User:  "test",
-			Value: 4.3,
+			Value: entity.NewMoney(430, entity.DefaultCurrency),
 		},
 		Lot: entity.Lot{
 			ID:           "id",
@@ -36,7 +35,7 @@ var (
 		Bid: entity.Bid{
 			Date:  time.Now().Add(5 * time.Hour),
 			User:  "test",
-			Value: 4.3,
+			Value: entity.NewMoney(430, entity.DefaultCurrency),
 		},
 		Lot: entity.Lot{
 			ID:           "id",
@@ -45,22 +44,52 @@ var (
 	}
 )
 
-func TestVehicleAsc(t *testing.T) {
-	items := []entity.Vehicle{v2, v1}
+func TestVehicleIndex(t *testing.T) {
+	idx := entity.NewVehicleIndex([]entity.Vehicle{v2, v1})
 
-	assert.Equal(t, items[0].ID, 2)
+	ve, ok := idx.ByID(1)
+	assert.True(t, ok)
+	assert.Equal(t, v1, ve)
 
-	sort.Sort(entity.VehiclesAsc(items))
+	_, ok = idx.ByID(3)
+	assert.False(t, ok)
+}
+
+func TestVehicle_Version(t *testing.T) {
+	changed := v1
+	changed.Bid.Value = entity.NewMoney(431, entity.DefaultCurrency)
 
-	assert.Equal(t, items[0].ID, 1)
+	assert.Len(t, v1.Version(), 16)
+	assert.Equal(t, v1.Version(), v1.Version())
+	assert.NotEqual(t, v1.Version(), changed.Version())
+	assert.NotEqual(t, v1.Version(), v2.Version())
 }
 
-func TestVehicleDesc(t *testing.T) {
-	items := []entity.Vehicle{v1, v2}
+func TestMeetsReserve(t *testing.T) {
+	price := func(amount int64, currency string) *entity.Money {
+		m := entity.NewMoney(amount, currency)
+		return &m
+	}
 
-	assert.Equal(t, items[0].ID, 1)
+	testCases := []struct {
+		desc    string
+		bid     entity.Bid
+		reserve *entity.Money
+		want    bool
+	}{
+		{desc: "must meet when there is no reserve", want: true},
+		{desc: "must meet when the reserve is zero", reserve: price(0, ""), want: true},
+		{desc: "must not meet without bids", reserve: price(100000, ""), want: false},
+		{desc: "must not meet below the reserve", bid: entity.Bid{User: "test", Value: entity.NewMoney(99999, "")}, reserve: price(100000, ""), want: false},
+		{desc: "must meet at the reserve", bid: entity.Bid{User: "test", Value: entity.NewMoney(100000, "")}, reserve: price(100000, ""), want: true},
+		{desc: "must not meet in another currency", bid: entity.Bid{User: "test", Value: entity.NewMoney(200000, "USD")}, reserve: price(100000, ""), want: false},
+	}
 
-	sort.Sort(entity.VehiclesDesc(items))
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ve := entity.Vehicle{Bid: tt.bid, Reserve: tt.reserve}
 
-	assert.Equal(t, items[0].ID, 2)
+			assert.Equal(t, tt.want, ve.MeetsReserve())
+		})
+	}
 }
@@ -1,15 +1,18 @@
 package legacy
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
 	"maga-auctions/entity"
 	"maga-auctions/utils"
 	"net/http"
 	"regexp"
-	"time"
+	"sync/atomic"
 )
 
 var (
@@ -17,6 +20,17 @@ var (
 	Client utils.HTTPClient
 	// APIURI is legacy service url
 	APIURI, method, dateLayout string
+	// InvalidBidDate is the policy for the rows whose bid date cannot be parsed
+	InvalidBidDate string
+
+	anomalies uint64
+)
+
+// Policies for the rows whose bid date cannot be parsed
+const (
+	SkipInvalidDate = "skip" // drops the row
+	ZeroInvalidDate = "zero" // keeps the row with a zero bid date
+	FailInvalidDate = "fail" // fails the whole call
 )
 
 func init() {
@@ -24,6 +38,16 @@ func init() {
 	APIURI = utils.EnvVars.Legacy.URI
 	method = "POST"
 	dateLayout = "02/01/2006 - 15:04"
+	InvalidBidDate = utils.EnvVars.Legacy.InvalidBidDate
+
+	if InvalidBidDate == "" {
+		InvalidBidDate = SkipInvalidDate
+	}
+}
+
+// DecodeAnomalies counts the legacy rows degraded since the process started
+func DecodeAnomalies() uint64 {
+	return atomic.LoadUint64(&anomalies)
 }
 
 // API contract
@@ -34,20 +58,25 @@ type API interface {
 	Delete(ctx context.Context, id int) error
 }
 
+// Finder is a legacy API that looks a vehicle up by ID without listing them all, ErrNotFound when it is missing
+type Finder interface {
+	Find(ctx context.Context, id int) (entity.Vehicle, error)
+}
+
 type srv struct{}
 
 // VehicleLegacy is legacy entity
 type VehicleLegacy struct {
-	ID             int     `json:"ID,omitempty"`
-	DataLance      string  `json:"DATALANCE"`
-	Lote           string  `json:"LOTE"`
-	CodigoControle string  `json:"CODIGOCONTROLE"`
-	Marca          string  `json:"MARCA"`
-	Modelo         string  `json:"MODELO"`
-	AnoFabricacao  int     `json:"ANOFABRICACAO"`
-	AnoModelo      int     `json:"ANOMODELO"`
-	ValorLance     float32 `json:"VALORLANCE"`
-	UsuarioLance   string  `json:"USUARIOLANCE"`
+	ID             int    `json:"ID,omitempty"`
+	DataLance      string `json:"DATALANCE"`
+	Lote           string `json:"LOTE"`
+	CodigoControle string `json:"CODIGOCONTROLE"`
+	Marca          string `json:"MARCA"`
+	Modelo         string `json:"MODELO"`
+	AnoFabricacao  int    `json:"ANOFABRICACAO"`
+	AnoModelo      int    `json:"ANOMODELO"`
+	ValorLance     Amount `json:"VALORLANCE"`
+	UsuarioLance   string `json:"USUARIOLANCE"`
 }
 
 type body struct {
@@ -55,13 +84,27 @@ type body struct {
 	Veiculo  VehicleLegacy `json:"VEICULO,omitempty"`
 }
 
+// upstreamStatus builds the error for an unexpected legacy api status code
+func upstreamStatus(res *http.Response) error {
+	e := ErrUpstreamStatus{Code: res.StatusCode}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1<<10))
+		e.Body = string(b)
+	}
+
+	return e
+}
+
 // NewAPI returns a planet service instance
 func NewAPI() API {
 	return &srv{}
 }
 
-func (s srv) Get(ctx context.Context) ([]entity.Vehicle, error) {
-	req, err := utils.MakeRequest(method, APIURI, body{Operacao: "consultar"})
+// do sends the operation to the legacy api and returns the response body bound to ctx
+func (s srv) do(ctx context.Context, b body) (io.ReadCloser, error) {
+	req, err := utils.MakeRequest(ctx, method, APIURI, b)
 
 	if err != nil {
 		return nil, err
@@ -70,48 +113,62 @@ func (s srv) Get(ctx context.Context) ([]entity.Vehicle, error) {
 	res, err := Client.Do(req)
 
 	if err != nil {
-		return nil, err
+		return nil, requestError(ctx, err)
 	}
 
 	if res.StatusCode != 200 {
-		return nil, errors.New("an error occurred while requesting the legacy api")
+		return nil, upstreamStatus(res)
 	}
 
-	defer res.Body.Close()
+	if res.Body == nil {
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	}
 
-	items := []VehicleLegacy{}
-	err = json.NewDecoder(res.Body).Decode(&items)
+	return utils.NewContextReader(ctx, res.Body), nil
+}
+
+func (s srv) Get(ctx context.Context) ([]entity.Vehicle, error) {
+	rc, err := s.do(ctx, body{Operacao: "consultar"})
 
 	if err != nil {
 		return nil, err
 	}
 
+	defer rc.Close()
+
+	items := []VehicleLegacy{}
+	err = json.NewDecoder(rc).Decode(&items)
+
+	if err != nil {
+		return nil, decodeError(ctx, err)
+	}
+
 	v := []entity.Vehicle{}
+	report := ReportFrom(ctx)
 
 	for i := 0; i < len(items); i++ {
 		l := items[i]
 
-		bidDate, err := time.Parse(dateLayout, l.DataLance)
+		item, err := ToVehicle(l)
 
 		if err != nil {
-			continue
-		}
+			if InvalidBidDate == FailInvalidDate {
+				return nil, ErrDecode{Err: fmt.Errorf("vehicle %d has an invalid DATALANCE %q", l.ID, l.DataLance)}
+			}
+
+			w := Warning{ID: l.ID, Field: "DATALANCE", Value: l.DataLance, Action: "skipped"}
+
+			if InvalidBidDate == ZeroInvalidDate {
+				w.Action = "zeroed"
+			}
 
-		item := entity.Vehicle{
-			ID:                l.ID,
-			Brand:             l.Marca,
-			Model:             l.Modelo,
-			ModelYear:         l.AnoModelo,
-			ManufacturingYear: l.AnoFabricacao,
-			Lot: entity.Lot{
-				ID:           l.Lote,
-				VehicleLotID: l.CodigoControle,
-			},
-			Bid: entity.Bid{
-				Date:  bidDate,
-				User:  l.UsuarioLance,
-				Value: l.ValorLance,
-			},
+			atomic.AddUint64(&anomalies, 1)
+			log.Printf("legacy vehicle %d has an invalid %s %q, %s", w.ID, w.Field, w.Value, w.Action)
+			report.addWarnings(w)
+
+			if w.Action == "skipped" {
+				continue
+			}
 		}
 
 		v = append(v, item)
@@ -121,87 +178,57 @@ func (s srv) Get(ctx context.Context) ([]entity.Vehicle, error) {
 }
 
 func (s srv) Create(ctx context.Context, vehicle *entity.Vehicle) error {
+	row, err := FromVehicle(*vehicle)
+
+	if err != nil {
+		return err
+	}
+
 	b := body{
 		Operacao: "criar",
-		Veiculo: VehicleLegacy{
-			Lote:           vehicle.Lot.ID,
-			CodigoControle: vehicle.Lot.VehicleLotID,
-			Marca:          vehicle.Brand,
-			Modelo:         vehicle.Model,
-			AnoFabricacao:  vehicle.ManufacturingYear,
-			AnoModelo:      vehicle.ModelYear,
-			DataLance:      vehicle.Bid.Date.Format(dateLayout),
-			UsuarioLance:   vehicle.Bid.User,
-		},
+		Veiculo:  row,
 	}
+	b.Veiculo.ID = 0
 
-	req, err := utils.MakeRequest(method, APIURI, b)
+	rc, err := s.do(ctx, b)
 
 	if err != nil {
 		return err
 	}
 
-	res, err := Client.Do(req)
+	defer rc.Close()
 
-	if err != nil {
-		return err
-	}
+	body, err := ioutil.ReadAll(rc)
 
-	if res.StatusCode != 200 {
-		return errors.New("an error occurred while requesting the legacy api")
+	if err != nil {
+		return decodeError(ctx, err)
 	}
 
-	body, _ := ioutil.ReadAll(res.Body)
-
-	err = json.Unmarshal(body, &vehicle)
+	var registered VehicleLegacy
+	err = json.Unmarshal(body, &registered)
 
 	if err != nil {
-		return err
+		return ErrDecode{Err: err}
 	}
 
+	vehicle.ID = registered.ID
+
 	return nil
 }
 
 func (s srv) Update(ctx context.Context, vehicle *entity.Vehicle) error {
-	b := body{
-		Operacao: "alterar",
-		Veiculo: VehicleLegacy{
-			ID:             vehicle.ID,
-			Marca:          vehicle.Brand,
-			Modelo:         vehicle.Model,
-			AnoFabricacao:  vehicle.ManufacturingYear,
-			AnoModelo:      vehicle.ModelYear,
-			Lote:           vehicle.Lot.ID,
-			CodigoControle: vehicle.Lot.VehicleLotID,
-			DataLance:      vehicle.Bid.Date.Format(dateLayout),
-			UsuarioLance:   vehicle.Bid.User,
-		},
-	}
-
-	req, err := utils.MakeRequest(method, APIURI, b)
-
-	if err != nil {
-		return err
-	}
-
-	res, err := Client.Do(req)
+	row, err := FromVehicle(*vehicle)
 
 	if err != nil {
 		return err
 	}
 
-	if res.StatusCode != 200 {
-		return errors.New("an error occurred while requesting the legacy api")
-	}
-
-	body, _ := ioutil.ReadAll(res.Body)
-	isError, _ := regexp.MatchString("nao encontrado", string(body))
-
-	if isError {
-		return errors.New("id not found")
+	b := body{
+		Operacao: "alterar",
+		Veiculo:  row,
 	}
 
-	return nil
+	return s.write(ctx, b)
 }
 
 func (s srv) Delete(ctx context.Context, id int) error {
@@ -210,27 +237,29 @@ func (s srv) Delete(ctx context.Context, id int) error {
 		Veiculo:  VehicleLegacy{ID: id},
 	}
 
-	req, err := utils.MakeRequest(method, APIURI, b)
+	return s.write(ctx, b)
+}
+
+// write sends an operation that answers with a message instead of the vehicle
+func (s srv) write(ctx context.Context, b body) error {
+	rc, err := s.do(ctx, b)
 
 	if err != nil {
 		return err
 	}
 
-	res, err := Client.Do(req)
+	defer rc.Close()
 
-	if err != nil {
-		return err
-	}
+	body, err := ioutil.ReadAll(rc)
 
-	if res.StatusCode != 200 {
-		return errors.New("an error occurred while requesting the legacy api")
+	if err != nil {
+		return decodeError(ctx, err)
 	}
 
-	body, _ := ioutil.ReadAll(res.Body)
 	isError, _ := regexp.MatchString("nao encontrado", string(body))
 
 	if isError {
-		return errors.New("id not found")
+		return ErrNotFound
 	}
 
 	return nil
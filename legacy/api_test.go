@@ -16,8 +16,8 @@ import (
 )
 
 var (
-	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
-	ve          = entity.Vehicle{
+	ctx = context.Background()
+	ve  = entity.Vehicle{
 		Brand:             "YAMAHA",
 		Model:             "T115 CRYPTON ED",
 		ModelYear:         2011,
@@ -39,7 +39,6 @@ func mockApiLegacy(apiURI, pathJSON string, statusCode int, doError error) {
 
 func TestGet(t *testing.T) {
 	t.Run("must return a list of vehicles", func(t *testing.T) {
-		defer cancel()
 		mockApiLegacy("https://test.com", "testdata/consultar_response_api.json", 200, nil)
 
 		api := legacy.NewAPI()
@@ -72,7 +71,7 @@ func TestGet_Errors(t *testing.T) {
 			desc:                "must return an error when failing the legacy api request",
 			apiURI:              "https://test.com",
 			legacyApiStatusCode: 500,
-			want:                "an error occurred while requesting the legacy api",
+			want:                "an error occurred while requesting the legacy api: status 500",
 		},
 		{
 			desc:                "must return an error when the object parse fails",
@@ -85,7 +84,6 @@ func TestGet_Errors(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
 			mockApiLegacy(tt.apiURI, tt.jsonPATH, tt.legacyApiStatusCode, tt.doError)
 
 			api := legacy.NewAPI()
@@ -98,7 +96,6 @@ func TestGet_Errors(t *testing.T) {
 
 func TestCreate(t *testing.T) {
 	t.Run("must register a vehicle", func(t *testing.T) {
-		defer cancel()
 		mockApiLegacy("https://test.com", "testdata/criar_response_api.json", 200, nil)
 
 		api := legacy.NewAPI()
@@ -130,7 +127,7 @@ func TestCreate_Errors(t *testing.T) {
 			desc:                "must return an error when failing the legacy api request",
 			apiURI:              "https://test.com",
 			legacyApiStatusCode: 500,
-			want:                "an error occurred while requesting the legacy api",
+			want:                "an error occurred while requesting the legacy api: status 500",
 		},
 		{
 			desc:                "must return an error when the object parse fails",
@@ -143,7 +140,6 @@ func TestCreate_Errors(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
 			mockApiLegacy(tt.apiURI, tt.jsonPATH, tt.legacyApiStatusCode, tt.doError)
 
 			api := legacy.NewAPI()
@@ -157,7 +153,6 @@ func TestCreate_Errors(t *testing.T) {
 
 func TestUpdate(t *testing.T) {
 	t.Run("must update a vehicle", func(t *testing.T) {
-		defer cancel()
 		mockApiLegacy("https://test.com", "testdata/alterar_response_api.json", 200, nil)
 
 		api := legacy.NewAPI()
@@ -189,7 +184,7 @@ func TestUpdate_Errors(t *testing.T) {
 			desc:                "must return an error when failing the legacy api request",
 			apiURI:              "https://test.com",
 			legacyApiStatusCode: 500,
-			want:                "an error occurred while requesting the legacy api",
+			want:                "an error occurred while requesting the legacy api: status 500",
 		},
 		{
 			desc:                "must return an error when id is not found",
@@ -214,7 +209,6 @@ func TestUpdate_Errors(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	t.Run("must delete vehicle", func(t *testing.T) {
-		defer cancel()
 		mockApiLegacy("https://test.com", "testdata/apagar_response_api.json", 200, nil)
 
 		api := legacy.NewAPI()
@@ -244,7 +238,7 @@ func TestDelete_Errors(t *testing.T) {
 		{
 			desc:                "must return an error when failing the legacy api request",
 			legacyApiStatusCode: 500,
-			want:                "an error occurred while requesting the legacy api",
+			want:                "an error occurred while requesting the legacy api: status 500",
 		},
 		{
 			desc:                "must return an error when id is not found",
@@ -256,7 +250,6 @@ func TestDelete_Errors(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
 			mockApiLegacy(tt.apiURI, tt.jsonPATH, tt.legacyApiStatusCode, tt.doError)
 
 			api := legacy.NewAPI()
@@ -267,3 +260,120 @@ func TestDelete_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestErrors_Types(t *testing.T) {
+	t.Run("must return ErrNotFound when id is not found", func(t *testing.T) {
+		mockApiLegacy("https://test.com", "testdata/apagar_response_error_api.json", 200, nil)
+
+		err := legacy.NewAPI().Delete(ctx, 1)
+
+		assert.True(t, errors.Is(err, legacy.ErrNotFound))
+	})
+
+	t.Run("must return ErrUpstreamStatus when the status is unexpected", func(t *testing.T) {
+		mockApiLegacy("https://test.com", "testdata/apagar_response_error_api.json", 503, nil)
+
+		err := legacy.NewAPI().Delete(ctx, 1)
+
+		var upstream legacy.ErrUpstreamStatus
+		assert.True(t, errors.As(err, &upstream))
+		assert.Equal(t, 503, upstream.Code)
+		assert.Contains(t, upstream.Body, "nao encontrado")
+	})
+
+	t.Run("must return ErrDecode when the payload is invalid", func(t *testing.T) {
+		mockApiLegacy("https://test.com", "testdata/consultar_response_error_api.json", 200, nil)
+
+		_, err := legacy.NewAPI().Get(ctx)
+
+		var decode legacy.ErrDecode
+		assert.True(t, errors.As(err, &decode))
+	})
+
+	t.Run("must return ErrTimeout when the request times out", func(t *testing.T) {
+		mockApiLegacy("https://test.com", "", 0, context.DeadlineExceeded)
+
+		_, err := legacy.NewAPI().Get(ctx)
+
+		assert.True(t, errors.Is(err, legacy.ErrTimeout))
+	})
+}
+
+func TestContext(t *testing.T) {
+	t.Run("must bind the context to the request", func(t *testing.T) {
+		c, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		var got *http.Request
+		mockApiLegacy("https://test.com", "testdata/apagar_response_api.json", 200, nil)
+		do := mock_legacy.GetDoFunc
+		mock_legacy.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+			got = req
+			return do(req)
+		}
+
+		err := legacy.NewAPI().Delete(c, 1)
+
+		assert.Nil(t, err)
+		_, ok := got.Context().Deadline()
+		assert.True(t, ok)
+	})
+
+	t.Run("must return ErrTimeout when the deadline expires while reading the body", func(t *testing.T) {
+		c, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		mockApiLegacy("https://test.com", "testdata/consultar_response_api.json", 200, nil)
+
+		_, err := legacy.NewAPI().Get(c)
+
+		assert.True(t, errors.Is(err, legacy.ErrTimeout))
+	})
+
+	t.Run("must stop when the caller cancels", func(t *testing.T) {
+		c, cancel := context.WithCancel(context.Background())
+		cancel()
+		mockApiLegacy("https://test.com", "testdata/apagar_response_api.json", 200, nil)
+
+		err := legacy.NewAPI().Delete(c, 1)
+
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.False(t, errors.Is(err, legacy.ErrTimeout))
+	})
+}
+
+func TestGet_InvalidBidDate(t *testing.T) {
+	testCases := []struct {
+		desc, policy, wantAction string
+		wantLen                  int
+		wantErr                  bool
+	}{
+		{desc: "must skip the row", policy: legacy.SkipInvalidDate, wantLen: 763, wantAction: "skipped"},
+		{desc: "must keep the row with a zero date", policy: legacy.ZeroInvalidDate, wantLen: 764, wantAction: "zeroed"},
+		{desc: "must fail the call", policy: legacy.FailInvalidDate, wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			mockApiLegacy("https://test.com", "testdata/consultar_response_api.json", 200, nil)
+			legacy.InvalidBidDate = tt.policy
+			defer func() { legacy.InvalidBidDate = legacy.SkipInvalidDate }()
+
+			c, report := legacy.WithReport(ctx)
+			before := legacy.DecodeAnomalies()
+
+			items, err := legacy.NewAPI().Get(c)
+
+			if tt.wantErr {
+				var decode legacy.ErrDecode
+				assert.True(t, errors.As(err, &decode))
+				assert.EqualError(t, err, `vehicle 305 has an invalid DATALANCE "22/08/2020"`)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Len(t, items, tt.wantLen)
+			assert.Equal(t, []legacy.Warning{{ID: 305, Field: "DATALANCE", Value: "22/08/2020", Action: tt.wantAction}}, report.Warnings())
+			assert.Equal(t, before+1, legacy.DecodeAnomalies())
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package legacy
+
+import (
+	"context"
+	"errors"
+	"maga-auctions/entity"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen is returned while the legacy api is considered down
+var ErrCircuitOpen = errors.New("legacy api is unavailable")
+
+// BreakerPolicy configures when the circuit opens and closes again
+type BreakerPolicy struct {
+	FailureThreshold int           // consecutive failures that open the circuit
+	SuccessThreshold int           // consecutive half-open successes that close the circuit
+	CoolDown         time.Duration // time the circuit stays open before a new attempt
+}
+
+// Breaker is a legacy API that stops calling the upstream while it is failing
+type Breaker interface {
+	API
+	State() string
+}
+
+type breaker struct {
+	api    API
+	policy BreakerPolicy
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	successes int
+	openedAt  time.Time
+	probing   bool
+
+	snapshot   *cacheEntry
+	snapshotAt time.Time
+}
+
+// NewBreaker decorates api with a circuit breaker that serves the last known-good Get while open
+func NewBreaker(api API, policy BreakerPolicy) Breaker {
+	if policy.SuccessThreshold < 1 {
+		policy.SuccessThreshold = 1
+	}
+
+	return &breaker{
+		api:    api,
+		policy: policy,
+		state:  StateClosed,
+	}
+}
+
+func (b *breaker) Get(ctx context.Context) ([]entity.Vehicle, error) {
+	if !b.allow() {
+		b.mu.Lock()
+		snapshot, at := b.snapshot, b.snapshotAt
+		b.mu.Unlock()
+
+		if snapshot == nil {
+			return nil, ErrCircuitOpen
+		}
+
+		ReportFrom(ctx).markStale(at)
+		return snapshot.serve(ctx), nil
+	}
+
+	inner, report := WithReport(ctx)
+	items, err := b.api.Get(inner)
+	b.record(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &cacheEntry{items: copyVehicles(items), warnings: report.Warnings()}
+
+	b.mu.Lock()
+	b.snapshot = snapshot
+	b.snapshotAt = time.Now()
+	b.mu.Unlock()
+
+	ReportFrom(ctx).addWarnings(snapshot.warnings...)
+	return items, nil
+}
+
+func (b *breaker) Create(ctx context.Context, vehicle *entity.Vehicle) error {
+	return b.call(func() error { return b.api.Create(ctx, vehicle) })
+}
+
+func (b *breaker) Update(ctx context.Context, vehicle *entity.Vehicle) error {
+	return b.call(func() error { return b.api.Update(ctx, vehicle) })
+}
+
+func (b *breaker) Delete(ctx context.Context, id int) error {
+	return b.call(func() error { return b.api.Delete(ctx, id) })
+}
+
+func (b *breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.coolDown()
+	return b.state
+}
+
+func (b *breaker) call(fn func() error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+
+	err := fn()
+	b.record(err)
+
+	return err
+}
+
+// allow tells whether a call may reach the upstream, letting a single probe through when half-open
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.coolDown()
+
+	switch b.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+
+		b.probing = true
+	}
+
+	return true
+}
+
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if failure(err) {
+		b.successes = 0
+		b.failures++
+
+		if b.state == StateHalfOpen || b.failures >= b.policy.FailureThreshold {
+			b.state = StateOpen
+			b.openedAt = time.Now()
+		}
+
+		return
+	}
+
+	b.failures = 0
+
+	if b.state == StateHalfOpen {
+		b.successes++
+
+		if b.successes >= b.policy.SuccessThreshold {
+			b.state = StateClosed
+			b.successes = 0
+		}
+	}
+}
+
+// coolDown moves an open circuit to half-open once the cool-down is over, b.mu must be held
+func (b *breaker) coolDown() {
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.policy.CoolDown {
+		b.state = StateHalfOpen
+		b.probing = false
+	}
+}
+
+// failure tells whether err says something about the health of the upstream
+func failure(err error) bool {
+	return err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrCurrency) && !errors.Is(err, context.Canceled)
+}
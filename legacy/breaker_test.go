@@ -0,0 +1,140 @@
+package legacy_test
+
+import (
+	"context"
+	"maga-auctions/entity"
+	"maga-auctions/legacy"
+	mock_legacy "maga-auctions/legacy/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var breakerPolicy = legacy.BreakerPolicy{
+	FailureThreshold: 2,
+	SuccessThreshold: 1,
+	CoolDown:         20 * time.Millisecond,
+}
+
+func TestBreaker_States(t *testing.T) {
+	t.Run("must open after consecutive failures and close after a successful probe", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		down := legacy.ErrUpstreamStatus{Code: 503}
+		api := mock_legacy.NewMockAPI(ctrl)
+		gomock.InOrder(
+			api.EXPECT().Delete(gomock.Any(), 1).Return(down),
+			api.EXPECT().Delete(gomock.Any(), 1).Return(down),
+			api.EXPECT().Delete(gomock.Any(), 1).Return(nil),
+		)
+
+		b := legacy.NewBreaker(api, breakerPolicy)
+
+		assert.Equal(t, down, b.Delete(ctx, 1))
+		assert.Equal(t, legacy.StateClosed, b.State())
+		assert.Equal(t, down, b.Delete(ctx, 1))
+		assert.Equal(t, legacy.StateOpen, b.State())
+		assert.Equal(t, legacy.ErrCircuitOpen, b.Delete(ctx, 1))
+
+		time.Sleep(25 * time.Millisecond)
+
+		assert.Equal(t, legacy.StateHalfOpen, b.State())
+		assert.Nil(t, b.Delete(ctx, 1))
+		assert.Equal(t, legacy.StateClosed, b.State())
+	})
+
+	t.Run("must open again when the probe fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).Return(nil, legacy.ErrTimeout).Times(3)
+
+		b := legacy.NewBreaker(api, breakerPolicy)
+		_, _ = b.Get(ctx)
+		_, _ = b.Get(ctx)
+
+		time.Sleep(25 * time.Millisecond)
+		_, err := b.Get(ctx)
+
+		assert.Equal(t, legacy.ErrTimeout, err)
+		assert.Equal(t, legacy.StateOpen, b.State())
+	})
+
+	t.Run("must not count business errors as failures", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Delete(gomock.Any(), 1).Return(legacy.ErrNotFound).Times(3)
+
+		b := legacy.NewBreaker(api, breakerPolicy)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, legacy.ErrNotFound, b.Delete(ctx, 1))
+		}
+
+		assert.Equal(t, legacy.StateClosed, b.State())
+	})
+}
+
+func TestBreaker_Stale(t *testing.T) {
+	t.Run("must serve the last known-good snapshot while open", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		gomock.InOrder(
+			api.EXPECT().Get(gomock.Any()).Return(cached, nil),
+			api.EXPECT().Get(gomock.Any()).Return(nil, legacy.ErrTimeout).Times(2),
+		)
+
+		b := legacy.NewBreaker(api, breakerPolicy)
+		_, _ = b.Get(ctx)
+		_, _ = b.Get(ctx)
+		_, _ = b.Get(ctx)
+
+		c, report := legacy.WithReport(context.Background())
+		items, err := b.Get(c)
+		stale, since := report.Stale()
+
+		assert.Nil(t, err)
+		assert.Equal(t, cached, items)
+		assert.True(t, stale)
+		assert.False(t, since.IsZero())
+	})
+
+	t.Run("must fail while open without a snapshot", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).Return(nil, legacy.ErrTimeout).Times(2)
+
+		b := legacy.NewBreaker(api, breakerPolicy)
+		_, _ = b.Get(ctx)
+		_, _ = b.Get(ctx)
+
+		items, err := b.Get(ctx)
+
+		assert.Nil(t, items)
+		assert.Equal(t, legacy.ErrCircuitOpen, err)
+	})
+
+	t.Run("must not mark fresh data as stale", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{}, nil)
+
+		c, report := legacy.WithReport(context.Background())
+		_, _ = legacy.NewBreaker(api, breakerPolicy).Get(c)
+		stale, _ := report.Stale()
+
+		assert.False(t, stale)
+	})
+}
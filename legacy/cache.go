@@ -0,0 +1,199 @@
+package legacy
+
+import (
+	"context"
+	"maga-auctions/entity"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// cacheLoadTimeout bounds the shared upstream call of a miss, no caller context can cancel it
+const cacheLoadTimeout = 20 * time.Second
+
+// Cache is a legacy API that keeps the last Get result in memory, indexed by ID for Find
+type Cache interface {
+	API
+	Finder
+	Stats() CacheStats
+}
+
+// CacheStats counts the Get lookups served by the cache
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type cache struct {
+	api   API
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu         sync.RWMutex
+	entry      *cacheEntry
+	expiresAt  time.Time
+	generation uint64
+
+	hits, misses uint64
+}
+
+// cacheEntry keeps the warnings of a Get so they are reported on every hit
+type cacheEntry struct {
+	items      []entity.Vehicle
+	index      entity.VehicleIndex
+	warnings   []Warning
+	staleSince time.Time
+}
+
+// NewCache decorates api with a read-through cache of Get results that lives for ttl
+func NewCache(api API, ttl time.Duration) Cache {
+	return &cache{
+		api: api,
+		ttl: ttl,
+	}
+}
+
+func (c *cache) Get(ctx context.Context) ([]entity.Vehicle, error) {
+	entry, err := c.lookup(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.serve(ctx), nil
+}
+
+// Find looks the vehicle up in the index of the cached result instead of copying the whole list
+func (c *cache) Find(ctx context.Context, id int) (entity.Vehicle, error) {
+	entry, err := c.lookup(ctx)
+
+	if err != nil {
+		return entity.Vehicle{}, err
+	}
+
+	entry.report(ctx)
+	v, ok := entry.index.ByID(id)
+
+	if !ok {
+		return entity.Vehicle{}, ErrNotFound
+	}
+
+	return v, nil
+}
+
+// lookup returns the cached result, or loads it on a miss
+func (c *cache) lookup(ctx context.Context) (*cacheEntry, error) {
+	c.mu.RLock()
+	entry, expiresAt, generation := c.entry, c.expiresAt, c.generation
+	c.mu.RUnlock()
+
+	if entry != nil && time.Now().Before(expiresAt) {
+		atomic.AddUint64(&c.hits, 1)
+		return entry, nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+
+	// concurrent misses of the same generation share one upstream call, it runs on its own context
+	// so a caller that gives up does not fail the call the others are waiting on
+	ch := c.group.DoChan(strconv.FormatUint(generation, 10), func() (interface{}, error) {
+		return c.load(generation)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(*cacheEntry), nil
+	case <-ctx.Done():
+		return nil, requestError(ctx, ctx.Err())
+	}
+}
+
+// load gets the vehicles from upstream and caches them unless a write invalidated the generation meanwhile
+func (c *cache) load(generation uint64) (*cacheEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheLoadTimeout)
+	defer cancel()
+
+	ctx, report := WithReport(ctx)
+	items, err := c.api.Get(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the index is built once per result and shared by every Find until the entry goes
+	entry := &cacheEntry{items: items, index: entity.NewVehicleIndex(items), warnings: report.Warnings()}
+
+	// a snapshot the breaker served is not cached so the next miss sees the api as soon as it is back
+	if stale, since := report.Stale(); stale {
+		entry.staleSince = since
+		return entry, nil
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entry = entry
+		c.expiresAt = time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+
+	return entry, nil
+}
+
+func (c *cache) Create(ctx context.Context, vehicle *entity.Vehicle) error {
+	defer c.invalidate()
+	return c.api.Create(ctx, vehicle)
+}
+
+func (c *cache) Update(ctx context.Context, vehicle *entity.Vehicle) error {
+	defer c.invalidate()
+	return c.api.Update(ctx, vehicle)
+}
+
+func (c *cache) Delete(ctx context.Context, id int) error {
+	defer c.invalidate()
+	return c.api.Delete(ctx, id)
+}
+
+func (c *cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
+
+// invalidate drops the cached result and any Get still in flight
+func (c *cache) invalidate() {
+	c.mu.Lock()
+	c.entry = nil
+	c.generation++
+	c.mu.Unlock()
+}
+
+// serve reports the entry to ctx and returns a copy of its items
+func (e *cacheEntry) serve(ctx context.Context) []entity.Vehicle {
+	e.report(ctx)
+	return copyVehicles(e.items)
+}
+
+// report tells ctx the warnings and the staleness of the entry
+func (e *cacheEntry) report(ctx context.Context) {
+	if !e.staleSince.IsZero() {
+		ReportFrom(ctx).markStale(e.staleSince)
+	}
+
+	ReportFrom(ctx).addWarnings(e.warnings...)
+}
+
+// copyVehicles protects the cached result from callers that sort or filter in place
+func copyVehicles(items []entity.Vehicle) []entity.Vehicle {
+	out := make([]entity.Vehicle, len(items))
+	copy(out, items)
+	return out
+}
@@ -0,0 +1,280 @@
+package legacy_test
+
+import (
+	"context"
+	"errors"
+	"maga-auctions/entity"
+	"maga-auctions/legacy"
+	mock_legacy "maga-auctions/legacy/mocks"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var cached = []entity.Vehicle{{ID: 1, Brand: "RENAULT"}, {ID: 2, Brand: "FIAT"}}
+
+func TestCache_Get(t *testing.T) {
+	t.Run("must serve the cached result until the ttl expires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).Return(cached, nil).Times(2)
+
+		c := legacy.NewCache(api, 50*time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			items, err := c.Get(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, cached, items)
+		}
+
+		time.Sleep(60 * time.Millisecond)
+		_, _ = c.Get(ctx)
+
+		assert.Equal(t, legacy.CacheStats{Hits: 2, Misses: 2}, c.Stats())
+	})
+
+	t.Run("must not share the cached slice with callers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{{ID: 1}, {ID: 2}}, nil)
+
+		c := legacy.NewCache(api, time.Minute)
+
+		items, _ := c.Get(ctx)
+		items[0], items[1] = items[1], items[0]
+		again, _ := c.Get(ctx)
+
+		assert.Equal(t, 1, again[0].ID)
+	})
+
+	t.Run("must share one upstream call between concurrent misses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		release := make(chan struct{})
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).DoAndReturn(func(interface{}) ([]entity.Vehicle, error) {
+			<-release
+			return cached, nil
+		}).Times(1)
+
+		c := legacy.NewCache(api, time.Minute)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				items, err := c.Get(ctx)
+				assert.Nil(t, err)
+				assert.Len(t, items, 2)
+			}()
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+	})
+
+	t.Run("must not fail the shared call when the first caller gives up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		release := make(chan struct{})
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]entity.Vehicle, error) {
+			<-release
+
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			return cached, nil
+		}).Times(1)
+
+		c := legacy.NewCache(api, time.Minute)
+
+		first, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() {
+			_, err := c.Get(first)
+			done <- err
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		waiting := make(chan []entity.Vehicle)
+		go func() {
+			items, err := c.Get(ctx)
+			assert.Nil(t, err)
+			waiting <- items
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		assert.Equal(t, context.Canceled, <-done)
+
+		close(release)
+		assert.Equal(t, cached, <-waiting)
+
+		items, err := c.Get(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, cached, items)
+	})
+
+	t.Run("must not cache errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		gomock.InOrder(
+			api.EXPECT().Get(gomock.Any()).Return(nil, errors.New("fail")),
+			api.EXPECT().Get(gomock.Any()).Return(cached, nil),
+		)
+
+		c := legacy.NewCache(api, time.Minute)
+
+		_, err := c.Get(ctx)
+		assert.NotNil(t, err)
+
+		items, err := c.Get(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, items, 2)
+	})
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	ve := entity.Vehicle{ID: 1}
+
+	testCases := []struct {
+		desc  string
+		write func(c legacy.Cache) error
+		mock  func(api *mock_legacy.MockAPI)
+	}{
+		{
+			desc:  "must invalidate on create",
+			write: func(c legacy.Cache) error { return c.Create(ctx, &ve) },
+			mock:  func(api *mock_legacy.MockAPI) { api.EXPECT().Create(gomock.Any(), &ve).Return(nil) },
+		},
+		{
+			desc:  "must invalidate on update",
+			write: func(c legacy.Cache) error { return c.Update(ctx, &ve) },
+			mock:  func(api *mock_legacy.MockAPI) { api.EXPECT().Update(gomock.Any(), &ve).Return(nil) },
+		},
+		{
+			desc:  "must invalidate on delete",
+			write: func(c legacy.Cache) error { return c.Delete(ctx, 1) },
+			mock:  func(api *mock_legacy.MockAPI) { api.EXPECT().Delete(gomock.Any(), 1).Return(legacy.ErrNotFound) },
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			api.EXPECT().Get(gomock.Any()).Return(cached, nil).Times(2)
+			tt.mock(api)
+
+			c := legacy.NewCache(api, time.Minute)
+
+			_, _ = c.Get(ctx)
+			_ = tt.write(c)
+			_, _ = c.Get(ctx)
+
+			assert.Equal(t, legacy.CacheStats{Hits: 0, Misses: 2}, c.Stats())
+		})
+	}
+}
+
+func TestCache_Warnings(t *testing.T) {
+	t.Run("must report the warnings of the cached result on every hit", func(t *testing.T) {
+		mockApiLegacy("https://test.com", "testdata/consultar_response_api.json", 200, nil)
+		c := legacy.NewCache(legacy.NewAPI(), time.Minute)
+
+		for i := 0; i < 2; i++ {
+			rc, report := legacy.WithReport(ctx)
+			_, err := c.Get(rc)
+
+			assert.Nil(t, err)
+			assert.Len(t, report.Warnings(), 1)
+		}
+
+		assert.Equal(t, legacy.CacheStats{Hits: 1, Misses: 1}, c.Stats())
+	})
+}
+
+func TestCache_Stale(t *testing.T) {
+	t.Run("must report the breaker snapshot as stale and not cache it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		gomock.InOrder(
+			api.EXPECT().Get(gomock.Any()).Return(cached, nil),
+			api.EXPECT().Get(gomock.Any()).Return(nil, legacy.ErrTimeout).Times(2),
+		)
+
+		c := legacy.NewCache(legacy.NewBreaker(api, breakerPolicy), 10*time.Millisecond)
+		_, _ = c.Get(ctx)
+		time.Sleep(15 * time.Millisecond)
+		_, _ = c.Get(ctx)
+		_, _ = c.Get(ctx)
+
+		for i := 0; i < 2; i++ {
+			rc, report := legacy.WithReport(ctx)
+			items, err := c.Get(rc)
+			stale, _ := report.Stale()
+
+			assert.Nil(t, err)
+			assert.Equal(t, cached, items)
+			assert.True(t, stale)
+		}
+
+		assert.Equal(t, legacy.CacheStats{Hits: 0, Misses: 5}, c.Stats())
+	})
+}
+
+func TestCache_Find(t *testing.T) {
+	t.Run("must look the vehicles up in the cached result", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).Return(cached, nil).Times(1)
+
+		c := legacy.NewCache(api, time.Minute)
+
+		v, err := c.Find(ctx, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, cached[1], v)
+
+		v, err = c.Find(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, cached[0], v)
+
+		_, err = c.Find(ctx, 3)
+		assert.Equal(t, legacy.ErrNotFound, err)
+
+		assert.Equal(t, legacy.CacheStats{Hits: 2, Misses: 1}, c.Stats())
+	})
+
+	t.Run("must not find a vehicle the upstream call failed to list", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Get(gomock.Any()).Return(nil, legacy.ErrTimeout)
+
+		_, err := legacy.NewCache(api, time.Minute).Find(ctx, 1)
+
+		assert.Equal(t, legacy.ErrTimeout, err)
+	})
+}
@@ -0,0 +1,78 @@
+package legacy
+
+import (
+	"errors"
+	"fmt"
+	"maga-auctions/entity"
+	"strings"
+	"time"
+)
+
+// ErrCurrency is returned when an amount in another currency than the default one would be sent to the legacy api
+var ErrCurrency = fmt.Errorf("legacy api only takes amounts in %s", entity.DefaultCurrency)
+
+// Amount is a legacy amount in minor units of the default currency, always a json number
+type Amount int64
+
+// MarshalJSON writes the amount as an exact json number
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return entity.NewMoney(int64(a), entity.DefaultCurrency).MarshalJSON()
+}
+
+// UnmarshalJSON reads the amount from a json number or string, the legacy api has no currencies
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(b)), "{") {
+		return errors.New("legacy amount must be a number")
+	}
+
+	var m entity.Money
+
+	if err := m.UnmarshalJSON(b); err != nil {
+		return err
+	}
+
+	*a = Amount(m.Amount)
+	return nil
+}
+
+// ToVehicle maps a legacy row to the vehicle entity, the error tells the bid date could not be parsed
+func ToVehicle(l VehicleLegacy) (entity.Vehicle, error) {
+	bidDate, err := time.Parse(dateLayout, l.DataLance)
+
+	return entity.Vehicle{
+		ID:                l.ID,
+		Brand:             l.Marca,
+		Model:             l.Modelo,
+		ModelYear:         l.AnoModelo,
+		ManufacturingYear: l.AnoFabricacao,
+		Lot: entity.Lot{
+			ID:           l.Lote,
+			VehicleLotID: l.CodigoControle,
+		},
+		Bid: entity.Bid{
+			Date:  bidDate,
+			User:  l.UsuarioLance,
+			Value: entity.NewMoney(int64(l.ValorLance), ""),
+		},
+	}, err
+}
+
+// FromVehicle maps the vehicle entity to a legacy row, the bid must be in the default currency
+func FromVehicle(v entity.Vehicle) (VehicleLegacy, error) {
+	if v.Bid.Value.CurrencyCode() != entity.DefaultCurrency {
+		return VehicleLegacy{}, ErrCurrency
+	}
+
+	return VehicleLegacy{
+		ID:             v.ID,
+		DataLance:      v.Bid.Date.Format(dateLayout),
+		Lote:           v.Lot.ID,
+		CodigoControle: v.Lot.VehicleLotID,
+		Marca:          v.Brand,
+		Modelo:         v.Model,
+		AnoFabricacao:  v.ManufacturingYear,
+		AnoModelo:      v.ModelYear,
+		ValorLance:     Amount(v.Bid.Value.Amount),
+		UsuarioLance:   v.Bid.User,
+	}, nil
+}
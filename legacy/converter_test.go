@@ -0,0 +1,143 @@
+package legacy_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"maga-auctions/entity"
+	"maga-auctions/legacy"
+	mock_legacy "maga-auctions/legacy/mocks"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readLegacy(t *testing.T, path string, out interface{}) {
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(b, out))
+}
+
+func TestConverter_RoundTrip(t *testing.T) {
+	t.Run("must keep every legacy row of the listing", func(t *testing.T) {
+		var rows []legacy.VehicleLegacy
+		readLegacy(t, "../api/controller/testdata/consultar_response_api.json", &rows)
+
+		for _, l := range rows {
+			v, err := legacy.ToVehicle(l)
+			assert.Nil(t, err)
+
+			row, err := legacy.FromVehicle(v)
+			assert.Nil(t, err)
+			assert.Equal(t, l, row)
+		}
+	})
+
+	t.Run("must keep the registered legacy row", func(t *testing.T) {
+		var l legacy.VehicleLegacy
+		readLegacy(t, "../api/controller/testdata/criar_response_api.json", &l)
+
+		v, err := legacy.ToVehicle(l)
+		assert.Nil(t, err)
+
+		row, err := legacy.FromVehicle(v)
+		assert.Nil(t, err)
+		assert.Equal(t, l, row)
+	})
+
+	t.Run("must keep the vehicle with its bid", func(t *testing.T) {
+		v := entity.Vehicle{
+			ID:                760,
+			Brand:             "IVECO",
+			Model:             "EUROCARGO 260E25N",
+			ModelYear:         2012,
+			ManufacturingYear: 2011,
+			Lot:               entity.Lot{ID: "0068", VehicleLotID: "126845"},
+			Bid: entity.Bid{
+				Date:  time.Date(2020, 8, 27, 10, 20, 0, 0, time.UTC),
+				Value: entity.NewMoney(7500000, ""),
+				User:  "ALDOBARROSO",
+			},
+		}
+
+		row, err := legacy.FromVehicle(v)
+		assert.Nil(t, err)
+
+		got, err := legacy.ToVehicle(row)
+
+		assert.Nil(t, err)
+		assert.Equal(t, v, got)
+	})
+}
+
+func TestConverter_Currency(t *testing.T) {
+	t.Run("must send the bid value as a number", func(t *testing.T) {
+		row, err := legacy.FromVehicle(entity.Vehicle{ID: 760, Bid: entity.Bid{Value: entity.NewMoney(7500050, entity.DefaultCurrency)}})
+		assert.Nil(t, err)
+
+		b, err := json.Marshal(row)
+
+		assert.Nil(t, err)
+		assert.Contains(t, string(b), `"VALORLANCE":75000.5`)
+	})
+
+	t.Run("must refuse a bid in another currency", func(t *testing.T) {
+		_, err := legacy.FromVehicle(entity.Vehicle{ID: 760, Bid: entity.Bid{Value: entity.NewMoney(100, "USD")}})
+
+		assert.Equal(t, legacy.ErrCurrency, err)
+	})
+
+	t.Run("must refuse a bid value that is not a number", func(t *testing.T) {
+		var row legacy.VehicleLegacy
+
+		err := json.Unmarshal([]byte(`{"VALORLANCE":{"amount":1,"currency":"USD"}}`), &row)
+
+		assert.EqualError(t, err, "legacy amount must be a number")
+	})
+}
+
+func TestConverter_Writes(t *testing.T) {
+	bid := entity.Vehicle{
+		ID:  760,
+		Bid: entity.Bid{Date: time.Date(2020, 8, 27, 10, 20, 0, 0, time.UTC), Value: entity.NewMoney(1500000, ""), User: "ALLBARBOS"},
+	}
+
+	testCases := []struct {
+		desc, jsonPATH string
+		write          func(api legacy.API) error
+	}{
+		{
+			desc:     "must send the bid value on create",
+			jsonPATH: "testdata/criar_response_api.json",
+			write:    func(api legacy.API) error { v := bid; return api.Create(ctx, &v) },
+		},
+		{
+			desc:     "must send the bid value on update",
+			jsonPATH: "testdata/alterar_response_api.json",
+			write:    func(api legacy.API) error { v := bid; return api.Update(ctx, &v) },
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			var sent struct {
+				Veiculo legacy.VehicleLegacy `json:"VEICULO"`
+			}
+
+			mockApiLegacy("https://test.com", tt.jsonPATH, 200, nil)
+			do := mock_legacy.GetDoFunc
+			mock_legacy.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+				_ = json.NewDecoder(req.Body).Decode(&sent)
+				return do(req)
+			}
+
+			err := tt.write(legacy.NewAPI())
+
+			assert.Nil(t, err)
+			assert.Equal(t, legacy.Amount(1500000), sent.Veiculo.ValorLance)
+			assert.Equal(t, "27/08/2020 - 10:20", sent.Veiculo.DataLance)
+			assert.Equal(t, "ALLBARBOS", sent.Veiculo.UsuarioLance)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package legacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+var (
+	// ErrNotFound is returned when the legacy api does not know the vehicle
+	ErrNotFound = errors.New("id not found")
+	// ErrTimeout is returned when the legacy api does not answer in time
+	ErrTimeout = errors.New("timeout while requesting the legacy api")
+)
+
+// ErrUpstreamStatus is returned when the legacy api answers with an unexpected status code
+type ErrUpstreamStatus struct {
+	Code int
+	Body string
+}
+
+func (e ErrUpstreamStatus) Error() string {
+	return fmt.Sprintf("an error occurred while requesting the legacy api: status %d", e.Code)
+}
+
+// ErrDecode is returned when the legacy api payload cannot be decoded
+type ErrDecode struct {
+	Err error
+}
+
+func (e ErrDecode) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the decoding error
+func (e ErrDecode) Unwrap() error {
+	return e.Err
+}
+
+// requestError tags the failures caused by ctx or by a slow legacy api with ErrTimeout
+func requestError(ctx context.Context, err error) error {
+	var ne net.Error
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+
+	if errors.As(err, &ne) && ne.Timeout() {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// decodeError tells a payload the legacy api got wrong from a read interrupted by ctx
+func decodeError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return requestError(ctx, err)
+	}
+
+	return ErrDecode{Err: err}
+}
@@ -0,0 +1,84 @@
+package legacy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Report collects what happened in the legacy api calls made on behalf of a request
+type Report struct {
+	mu         sync.Mutex
+	stale      bool
+	staleSince time.Time
+	warnings   []Warning
+}
+
+// Warning describes a legacy row that could not be decoded as is
+type Warning struct {
+	ID     int    `json:"id"`
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Action string `json:"action"`
+}
+
+type reportKey struct{}
+
+// WithReport returns a context that collects the report of the legacy api calls
+func WithReport(ctx context.Context) (context.Context, *Report) {
+	r := &Report{}
+	return context.WithValue(ctx, reportKey{}, r), r
+}
+
+// ReportFrom returns the report bound to ctx, nil when there is none
+func ReportFrom(ctx context.Context) *Report {
+	r, _ := ctx.Value(reportKey{}).(*Report)
+	return r
+}
+
+// Stale tells whether the data was served from a snapshot and when it was taken
+func (r *Report) Stale() (bool, time.Time) {
+	if r == nil {
+		return false, time.Time{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stale, r.staleSince
+}
+
+func (r *Report) markStale(since time.Time) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stale = true
+	r.staleSince = since
+}
+
+// Warnings returns the rows degraded while decoding the legacy data
+func (r *Report) Warnings() []Warning {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Warning(nil), r.warnings...)
+}
+
+func (r *Report) addWarnings(ws ...Warning) {
+	if r == nil || len(ws) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.warnings = append(r.warnings, ws...)
+}
@@ -0,0 +1,163 @@
+package legacy
+
+import (
+	"context"
+	"errors"
+	"maga-auctions/entity"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how the failed legacy api calls are retried
+type RetryPolicy struct {
+	MaxAttempts      int           // total attempts, including the first one
+	Backoff          time.Duration // delay before the second attempt, doubled on each new attempt
+	MaxBackoff       time.Duration // upper bound of the delay, zero means unbounded
+	Jitter           float64       // fraction of the delay that is randomized, from 0 to 1
+	RetryableStatus  []int         // legacy api status codes worth a new attempt
+	IdempotentCreate bool          // retries Create after checking the vehicle was not registered
+}
+
+type retry struct {
+	api    API
+	policy RetryPolicy
+}
+
+// NewRetry decorates api retrying the calls that fail with a retryable status code
+func NewRetry(api API, policy RetryPolicy) API {
+	return &retry{
+		api:    api,
+		policy: policy,
+	}
+}
+
+func (r *retry) Get(ctx context.Context) ([]entity.Vehicle, error) {
+	var items []entity.Vehicle
+
+	err := r.do(ctx, func(int) error {
+		var err error
+		items, err = r.api.Get(ctx)
+		return err
+	})
+
+	return items, err
+}
+
+func (r *retry) Create(ctx context.Context, vehicle *entity.Vehicle) error {
+	if !r.policy.IdempotentCreate {
+		return r.api.Create(ctx, vehicle)
+	}
+
+	return r.do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			// a failed attempt may have reached the legacy api anyway
+			registered, err := r.registered(ctx, vehicle)
+
+			if err != nil {
+				return err
+			}
+
+			if registered {
+				return nil
+			}
+		}
+
+		return r.api.Create(ctx, vehicle)
+	})
+}
+
+func (r *retry) Update(ctx context.Context, vehicle *entity.Vehicle) error {
+	return r.do(ctx, func(int) error {
+		return r.api.Update(ctx, vehicle)
+	})
+}
+
+func (r *retry) Delete(ctx context.Context, id int) error {
+	return r.do(ctx, func(attempt int) error {
+		err := r.api.Delete(ctx, id)
+
+		// the vehicle is gone when a previous attempt was applied but its answer got lost
+		if attempt > 1 && errors.Is(err, ErrNotFound) {
+			return nil
+		}
+
+		return err
+	})
+}
+
+// registered looks for the vehicle by its lot and control code, filling its ID when found
+func (r *retry) registered(ctx context.Context, vehicle *entity.Vehicle) (bool, error) {
+	items, err := r.api.Get(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range items {
+		if v.Lot.ID == vehicle.Lot.ID && v.Lot.VehicleLotID == vehicle.Lot.VehicleLotID {
+			vehicle.ID = v.ID
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// do runs call until it succeeds, fails for good or ctx has no time left for a new attempt
+func (r *retry) do(ctx context.Context, call func(attempt int) error) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = call(attempt)
+
+		if err == nil || attempt >= r.policy.MaxAttempts || !r.retryable(err) {
+			return err
+		}
+
+		delay := r.backoff(attempt)
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return err
+		}
+
+		t := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+func (r *retry) retryable(err error) bool {
+	var upstream ErrUpstreamStatus
+
+	if !errors.As(err, &upstream) {
+		return false
+	}
+
+	for _, code := range r.policy.RetryableStatus {
+		if code == upstream.Code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff is the exponential delay after the given attempt, minus a random jitter
+func (r *retry) backoff(attempt int) time.Duration {
+	d := r.policy.Backoff << uint(attempt-1)
+
+	if r.policy.MaxBackoff > 0 && (d > r.policy.MaxBackoff || d <= 0) {
+		d = r.policy.MaxBackoff
+	}
+
+	if r.policy.Jitter > 0 {
+		d -= time.Duration(float64(d) * r.policy.Jitter * rand.Float64())
+	}
+
+	return d
+}
@@ -0,0 +1,172 @@
+package legacy_test
+
+import (
+	"context"
+	"maga-auctions/entity"
+	"maga-auctions/legacy"
+	mock_legacy "maga-auctions/legacy/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var policy = legacy.RetryPolicy{
+	MaxAttempts:      3,
+	Backoff:          time.Millisecond,
+	MaxBackoff:       5 * time.Millisecond,
+	Jitter:           0.5,
+	RetryableStatus:  []int{502, 503},
+	IdempotentCreate: true,
+}
+
+func TestRetry_Get(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		errs    []error
+		wantErr error
+	}{
+		{
+			desc: "must retry until it succeeds",
+			errs: []error{legacy.ErrUpstreamStatus{Code: 503}, legacy.ErrUpstreamStatus{Code: 502}, nil},
+		},
+		{
+			desc:    "must give up after max attempts",
+			errs:    []error{legacy.ErrUpstreamStatus{Code: 503}, legacy.ErrUpstreamStatus{Code: 503}, legacy.ErrUpstreamStatus{Code: 503}},
+			wantErr: legacy.ErrUpstreamStatus{Code: 503},
+		},
+		{
+			desc:    "must not retry a status out of the policy",
+			errs:    []error{legacy.ErrUpstreamStatus{Code: 500}},
+			wantErr: legacy.ErrUpstreamStatus{Code: 500},
+		},
+		{
+			desc:    "must not retry a decode error",
+			errs:    []error{legacy.ErrDecode{Err: context.Canceled}},
+			wantErr: legacy.ErrDecode{Err: context.Canceled},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			var calls []*gomock.Call
+			for _, err := range tt.errs {
+				calls = append(calls, api.EXPECT().Get(gomock.Any()).Return(cached, err))
+			}
+			gomock.InOrder(calls...)
+
+			_, err := legacy.NewRetry(api, policy).Get(ctx)
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRetry_Deadline(t *testing.T) {
+	t.Run("must not wait beyond the context deadline", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(legacy.ErrUpstreamStatus{Code: 503}).Times(1)
+
+		slow := policy
+		slow.Backoff = time.Second
+		slow.MaxBackoff = 0
+
+		err := legacy.NewRetry(api, slow).Update(c, &entity.Vehicle{ID: 1})
+
+		assert.Equal(t, legacy.ErrUpstreamStatus{Code: 503}, err)
+	})
+}
+
+func TestRetry_Create(t *testing.T) {
+	t.Run("must not retry without the idempotency guard", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Create(gomock.Any(), gomock.Any()).Return(legacy.ErrUpstreamStatus{Code: 503}).Times(1)
+
+		unguarded := policy
+		unguarded.IdempotentCreate = false
+
+		err := legacy.NewRetry(api, unguarded).Create(ctx, &entity.Vehicle{})
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("must adopt the vehicle registered by a failed attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ve := entity.Vehicle{Lot: entity.Lot{ID: "0196", VehicleLotID: "56248"}}
+		registered := []entity.Vehicle{{ID: 42, Lot: ve.Lot}}
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		gomock.InOrder(
+			api.EXPECT().Create(gomock.Any(), &ve).Return(legacy.ErrUpstreamStatus{Code: 503}),
+			api.EXPECT().Get(gomock.Any()).Return(registered, nil),
+		)
+
+		err := legacy.NewRetry(api, policy).Create(ctx, &ve)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 42, ve.ID)
+	})
+
+	t.Run("must create again when the failed attempt was not registered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ve := entity.Vehicle{Lot: entity.Lot{ID: "0196", VehicleLotID: "56248"}}
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		gomock.InOrder(
+			api.EXPECT().Create(gomock.Any(), &ve).Return(legacy.ErrUpstreamStatus{Code: 503}),
+			api.EXPECT().Get(gomock.Any()).Return(cached, nil),
+			api.EXPECT().Create(gomock.Any(), &ve).Return(nil),
+		)
+
+		err := legacy.NewRetry(api, policy).Create(ctx, &ve)
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestRetry_Delete(t *testing.T) {
+	t.Run("must succeed when a lost attempt already deleted the vehicle", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		gomock.InOrder(
+			api.EXPECT().Delete(gomock.Any(), 1).Return(legacy.ErrUpstreamStatus{Code: 502}),
+			api.EXPECT().Delete(gomock.Any(), 1).Return(legacy.ErrNotFound),
+		)
+
+		err := legacy.NewRetry(api, policy).Delete(ctx, 1)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("must return not found on the first attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := mock_legacy.NewMockAPI(ctrl)
+		api.EXPECT().Delete(gomock.Any(), 1).Return(legacy.ErrNotFound)
+
+		err := legacy.NewRetry(api, policy).Delete(ctx, 1)
+
+		assert.Equal(t, legacy.ErrNotFound, err)
+	})
+}
@@ -0,0 +1,71 @@
+package lot
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// holds serializes the writes of the vehicles of each lot with its close
+type holds struct {
+	mu   sync.Mutex
+	byID map[string]*sync.RWMutex
+}
+
+func newHolds() *holds {
+	return &holds{byID: map[string]*sync.RWMutex{}}
+}
+
+func (h *holds) get(id string) *sync.RWMutex {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m, ok := h.byID[id]
+
+	if !ok {
+		m = &sync.RWMutex{}
+		h.byID[id] = m
+	}
+
+	return m
+}
+
+// share holds the lots for a write until the returned func is called, in id order so two writes cannot wait on each other
+func (h *holds) share(ids ...string) func() {
+	unique := map[string]bool{}
+
+	for _, id := range ids {
+		if strings.TrimSpace(id) != "" {
+			unique[id] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(unique))
+
+	for id := range unique {
+		sorted = append(sorted, id)
+	}
+
+	sort.Strings(sorted)
+	held := make([]*sync.RWMutex, 0, len(sorted))
+
+	for _, id := range sorted {
+		m := h.get(id)
+		m.RLock()
+		held = append(held, m)
+	}
+
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].RUnlock()
+		}
+	}
+}
+
+// exclusive holds the lot alone until the returned func is called
+func (h *holds) exclusive(id string) func() {
+	m := h.get(id)
+	m.Lock()
+
+	return m.Unlock
+}
@@ -0,0 +1,161 @@
+package lot
+
+import (
+	"context"
+	"fmt"
+	"maga-auctions/api/handler"
+	"maga-auctions/entity"
+	"maga-auctions/legacy"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VehicleSource lists the vehicles of a lot
+type VehicleSource interface {
+	ByLotID(ctx context.Context, lotID, order string) (*[]entity.Vehicle, error)
+}
+
+// Result of a vehicle in a settled lot
+type Result struct {
+	VehicleID    int          `json:"vehicleId"`
+	VehicleLotID string       `json:"vehicleLotId"`
+	Brand        string       `json:"brand"`
+	Model        string       `json:"model"`
+	Sold         bool         `json:"sold"`
+	Winner       string       `json:"winner,omitempty"`
+	HammerPrice  entity.Money `json:"hammerPrice"`
+	BidAt        *time.Time   `json:"bidAt,omitempty"`
+}
+
+// Results of a settled lot
+type Results struct {
+	LotID     string    `json:"lotId"`
+	ClosedAt  time.Time `json:"closedAt"`
+	SettledAt time.Time `json:"settledAt"`
+	Items     []Result  `json:"items"`
+}
+
+// Close freezes the lot and settles it with the final bid of each vehicle, a closed lot that failed to settle can be closed again
+// the lot is not settled from a stale snapshot of the legacy api
+func (s *srv) Close(ctx context.Context, id string, source VehicleSource) (*Results, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, handler.BadRequest{Message: "invalid lot id"}
+	}
+
+	// the writes that passed the lot check land before the lot is read
+	release := s.holds.exclusive(id)
+	defer release()
+
+	closedAt, err := s.freeze(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, report := legacy.WithReport(ctx)
+	vs, err := source.ByLotID(ctx, id, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a snapshot served while the legacy api is down may miss the last bids
+	if stale, _ := report.Stale(); stale {
+		return nil, handler.ServiceUnavailable{Message: fmt.Sprintf("lot %s cannot be settled from stale data of the legacy api", id)}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	a := s.auctions[id]
+
+	if !a.SettledAt.IsZero() {
+		return nil, handler.Conflict{Message: fmt.Sprintf("lot %s is %s", id, entity.AuctionSettled)}
+	}
+
+	a.SettledAt = now.UTC()
+	s.auctions[id] = a
+	s.transition(id, entity.AuctionSettled, now)
+
+	r := Results{LotID: id, ClosedAt: closedAt, SettledAt: a.SettledAt, Items: settle(*vs)}
+	s.results[id] = r
+
+	return copyResults(r), nil
+}
+
+func (s *srv) Results(ctx context.Context, id string) (*Results, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.results[id]
+
+	if !ok {
+		return nil, handler.NotFound{Message: "lot results not found"}
+	}
+
+	return copyResults(r), nil
+}
+
+// freeze ends the lot now unless it already ended and returns when it ended
+func (s *srv) freeze(id string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	a, ok := s.auctions[id]
+
+	if !ok {
+		a = entity.Auction{LotID: id, StartsAt: now.UTC(), ScheduledEndAt: now.UTC()}
+	}
+
+	switch a.StatusAt(now, s.closingWindow) {
+	case entity.AuctionSettled:
+		return time.Time{}, handler.Conflict{Message: fmt.Sprintf("lot %s is %s", id, entity.AuctionSettled)}
+	case entity.AuctionClosed:
+	default:
+		a.EndsAt = now.UTC()
+	}
+
+	s.auctions[id] = a
+	s.transition(id, entity.AuctionClosed, now)
+
+	return a.EndsAt, nil
+}
+
+// settle makes the last bid of each vehicle its hammer price when it meets the reserve, ordered by the control code
+func settle(vs []entity.Vehicle) []Result {
+	items := make([]Result, 0, len(vs))
+
+	for _, v := range vs {
+		r := Result{
+			VehicleID:    v.ID,
+			VehicleLotID: v.Lot.VehicleLotID,
+			Brand:        v.Brand,
+			Model:        v.Model,
+		}
+
+		if v.Bid.Placed() && v.MeetsReserve() {
+			date := v.Bid.Date
+			r.Sold = true
+			r.Winner = v.Bid.User
+			r.HammerPrice = v.Bid.Value
+			r.BidAt = &date
+		}
+
+		items = append(items, r)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].VehicleLotID < items[j].VehicleLotID
+	})
+
+	return items
+}
+
+func copyResults(r Results) *Results {
+	r.Items = append([]Result{}, r.Items...)
+
+	return &r
+}
@@ -0,0 +1,188 @@
+package lot_test
+
+import (
+	"context"
+	"errors"
+	"maga-auctions/api/handler"
+	"maga-auctions/entity"
+	"maga-auctions/legacy"
+	mock_legacy "maga-auctions/legacy/mocks"
+	"maga-auctions/lot"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type source struct {
+	vehicles []entity.Vehicle
+	err      error
+}
+
+func (s source) ByLotID(ctx context.Context, lotID, bidOrder string) (*[]entity.Vehicle, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &s.vehicles, nil
+}
+
+var vehicles = source{vehicles: []entity.Vehicle{
+	{
+		ID:    180,
+		Brand: "HONDA",
+		Model: "CIVIC SEDAN LXR",
+		Lot:   entity.Lot{ID: "0161", VehicleLotID: "733135"},
+		Bid:   entity.Bid{Date: start.Add(time.Minute), Value: entity.NewMoney(550000, ""), User: "Michaelnf"},
+	},
+	{
+		ID:    181,
+		Brand: "FIAT",
+		Model: "UNO",
+		Lot:   entity.Lot{ID: "0161", VehicleLotID: "733001"},
+		Bid:   entity.Bid{User: "-"},
+	},
+}}
+
+func TestClose(t *testing.T) {
+	c := &clock{now: start}
+	srv := newService(c)
+	_, _ = srv.Schedule(ctx, "0161", start, end)
+
+	_, err := srv.Results(ctx, "0161")
+	assert.EqualError(t, err, "lot results not found")
+
+	c.now = start.Add(30 * time.Minute)
+	results, err := srv.Close(ctx, "0161", vehicles)
+
+	bidAt := start.Add(time.Minute)
+	want := &lot.Results{
+		LotID:     "0161",
+		ClosedAt:  c.now,
+		SettledAt: c.now,
+		Items: []lot.Result{
+			{VehicleID: 181, VehicleLotID: "733001", Brand: "FIAT", Model: "UNO"},
+			{VehicleID: 180, VehicleLotID: "733135", Brand: "HONDA", Model: "CIVIC SEDAN LXR", Sold: true, Winner: "Michaelnf", HammerPrice: entity.NewMoney(550000, ""), BidAt: &bidAt},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, want, results)
+
+	stored, err := srv.Results(ctx, "0161")
+	assert.Nil(t, err)
+	assert.Equal(t, want, stored)
+
+	state, _ := srv.ByID(ctx, "0161")
+	assert.Equal(t, entity.AuctionSettled, state.Status)
+	assert.Equal(t, c.now, state.EndsAt)
+	assert.EqualError(t, srv.CanBid(ctx, "0161"), "lot 0161 is settled")
+	assert.EqualError(t, srv.CanChange(ctx, "0161"), "lot 0161 is settled")
+
+	_, err = srv.Close(ctx, "0161", vehicles)
+	assert.EqualError(t, err, "lot 0161 is settled")
+}
+
+func TestClose_Retry(t *testing.T) {
+	c := &clock{now: start}
+	srv := newService(c)
+
+	_, err := srv.Close(ctx, "0161", source{err: errors.New("legacy api error")})
+	assert.EqualError(t, err, "legacy api error")
+
+	state, _ := srv.ByID(ctx, "0161")
+	assert.Equal(t, entity.AuctionClosed, state.Status, "must keep the lot frozen")
+	assert.EqualError(t, srv.CanChange(ctx, "0161"), "lot 0161 is closed")
+
+	c.now = start.Add(time.Minute)
+	results, err := srv.Close(ctx, "0161", vehicles)
+
+	assert.Nil(t, err)
+	assert.Equal(t, start, results.ClosedAt)
+	assert.Equal(t, c.now, results.SettledAt)
+	assert.Len(t, results.Items, 2)
+}
+
+// legacySource lists every vehicle of the legacy api
+type legacySource struct {
+	api legacy.API
+}
+
+func (s legacySource) ByLotID(ctx context.Context, lotID, bidOrder string) (*[]entity.Vehicle, error) {
+	items, err := s.api.Get(ctx)
+	return &items, err
+}
+
+func TestClose_Stale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	gomock.InOrder(
+		api.EXPECT().Get(gomock.Any()).Return(vehicles.vehicles, nil),
+		api.EXPECT().Get(gomock.Any()).Return(nil, legacy.ErrTimeout).Times(2),
+	)
+
+	b := legacy.NewBreaker(api, legacy.BreakerPolicy{FailureThreshold: 2, CoolDown: time.Hour})
+	_, _ = b.Get(ctx)
+	_, _ = b.Get(ctx)
+	_, _ = b.Get(ctx)
+
+	srv := newService(&clock{now: start})
+	_, err := srv.Close(ctx, "0161", legacySource{api: b})
+
+	assert.Equal(t, handler.ServiceUnavailable{Message: "lot 0161 cannot be settled from stale data of the legacy api"}, err)
+
+	_, err = srv.Results(ctx, "0161")
+	assert.EqualError(t, err, "lot results not found")
+
+	state, _ := srv.ByID(ctx, "0161")
+	assert.Equal(t, entity.AuctionClosed, state.Status, "must keep the lot frozen")
+}
+
+func TestClose_Hold(t *testing.T) {
+	srv := newService(&clock{now: start})
+	_, _ = srv.Schedule(ctx, "0161", start, end)
+
+	release := srv.Hold("0161", "0196", "0161")
+	done := make(chan error)
+
+	go func() {
+		_, err := srv.Close(ctx, "0161", vehicles)
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("must wait for the writes that hold the lot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.Nil(t, srv.CanBid(ctx, "0161"), "must not freeze the lot under a write")
+
+	release()
+
+	assert.Nil(t, <-done)
+	assert.EqualError(t, srv.CanBid(ctx, "0161"), "lot 0161 is settled")
+}
+
+func TestClose_Errors(t *testing.T) {
+	_, err := newService(&clock{now: start}).Close(ctx, " ", vehicles)
+
+	assert.EqualError(t, err, "invalid lot id")
+}
+
+func TestClose_Reserve(t *testing.T) {
+	t.Run("must not sell the vehicles below the reserve", func(t *testing.T) {
+		srv := newService(&clock{now: start})
+		reserve := entity.NewMoney(600000, "")
+		below := vehicles.vehicles[0]
+		below.Reserve = &reserve
+
+		results, err := srv.Close(ctx, "0161", source{vehicles: []entity.Vehicle{below}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []lot.Result{{VehicleID: 180, VehicleLotID: "733135", Brand: "HONDA", Model: "CIVIC SEDAN LXR"}}, results.Items)
+	})
+}
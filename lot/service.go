@@ -0,0 +1,293 @@
+package lot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maga-auctions/api/handler"
+	"maga-auctions/entity"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Service contract
+type Service interface {
+	Schedule(ctx context.Context, id string, startsAt, endsAt time.Time) (*State, error)
+	ByID(ctx context.Context, id string) (*State, error)
+	CanBid(ctx context.Context, id string) error
+	CanChange(ctx context.Context, id string) error
+	Hold(ids ...string) func()
+	Close(ctx context.Context, id string, source VehicleSource) (*Results, error)
+	Results(ctx context.Context, id string) (*Results, error)
+	All(ctx context.Context, catalog VehicleCatalog, query ListQuery) (*SummaryPage, error)
+	Extend(ctx context.Context, id string) bool
+	Advance(ctx context.Context) []Transition
+	Run(ctx context.Context, every time.Duration)
+}
+
+// Kinds of the lot events
+const (
+	EventScheduled = "scheduled" // the schedule was set
+	EventMoved     = "moved"     // the state changed
+	EventExtended  = "extended"  // a late bid pushed the end out
+)
+
+// Event of the lot log
+type Event struct {
+	Kind   string     `json:"kind"`
+	At     time.Time  `json:"at"`
+	Status string     `json:"status,omitempty"`
+	EndsAt *time.Time `json:"endsAt,omitempty"`
+}
+
+// State of the auction of a lot at a moment
+type State struct {
+	entity.Auction
+	Status    string
+	Remaining time.Duration
+	Events    []Event
+}
+
+// Transition of the auction of a lot between two states
+type Transition struct {
+	LotID    string
+	From, To string
+	At       time.Time
+}
+
+type srv struct {
+	closingWindow time.Duration
+	extension     time.Duration
+	maxExtension  time.Duration
+	now           func() time.Time
+	holds         *holds
+
+	mu       sync.RWMutex
+	auctions map[string]entity.Auction
+	statuses map[string]string
+	events   map[string][]Event
+	results  map[string]Results
+}
+
+// Option configures the service
+type Option func(*srv)
+
+// WithClock sets the clock that drives the states
+func WithClock(now func() time.Time) Option {
+	return func(s *srv) {
+		s.now = now
+	}
+}
+
+// WithClosingWindow sets how long before the end a lot is closing
+func WithClosingWindow(d time.Duration) Option {
+	return func(s *srv) {
+		s.closingWindow = d
+	}
+}
+
+// WithSoftClose makes the bids taken while closing push the end out by extension, up to max past the scheduled end
+func WithSoftClose(extension, max time.Duration) Option {
+	return func(s *srv) {
+		s.extension = extension
+		s.maxExtension = max
+	}
+}
+
+// NewService returns a lot service instance
+func NewService(opts ...Option) Service {
+	s := &srv{
+		now:      time.Now,
+		holds:    newHolds(),
+		auctions: map[string]entity.Auction{},
+		statuses: map[string]string{},
+		events:   map[string][]Event{},
+		results:  map[string]Results{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *srv) Schedule(ctx context.Context, id string, startsAt, endsAt time.Time) (*State, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, handler.BadRequest{Message: "invalid lot id"}
+	}
+
+	if startsAt.IsZero() || endsAt.IsZero() || !endsAt.After(startsAt) {
+		return nil, handler.BadRequest{Message: "lot must end after it starts"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	current := s.auctions[id]
+
+	if status := current.StatusAt(now, s.closingWindow); status == entity.AuctionClosed || status == entity.AuctionSettled {
+		return nil, handler.Conflict{Message: fmt.Sprintf("lot %s is %s", id, status)}
+	}
+
+	a := entity.Auction{LotID: id, StartsAt: startsAt.UTC(), EndsAt: endsAt.UTC(), ScheduledEndAt: endsAt.UTC()}
+	s.auctions[id] = a
+	s.statuses[id] = a.StatusAt(now, s.closingWindow)
+	s.log(id, Event{Kind: EventScheduled, At: now.UTC(), Status: s.statuses[id], EndsAt: &a.EndsAt})
+
+	return s.state(a, now), nil
+}
+
+func (s *srv) ByID(ctx context.Context, id string) (*State, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, handler.BadRequest{Message: "invalid lot id"}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.auctions[id]
+
+	if !ok {
+		a = entity.Auction{LotID: id}
+	}
+
+	return s.state(a, s.now()), nil
+}
+
+func (s *srv) CanBid(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return nil
+	}
+
+	state, err := s.ByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if !entity.TakesBids(state.Status) {
+		return handler.Conflict{Message: fmt.Sprintf("lot %s is %s", id, state.Status)}
+	}
+
+	return nil
+}
+
+func (s *srv) CanChange(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return nil
+	}
+
+	state, err := s.ByID(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if state.Status == entity.AuctionClosed || state.Status == entity.AuctionSettled {
+		return handler.Conflict{Message: fmt.Sprintf("lot %s is %s", id, state.Status)}
+	}
+
+	return nil
+}
+
+// Hold keeps the lots from closing until the returned func is called, the writes of their vehicles check the lot and write under it
+func (s *srv) Hold(ids ...string) func() {
+	return s.holds.share(ids...)
+}
+
+// Extend pushes the end of the lot out when a bid is taken while it is closing, it is false when the end did not move
+func (s *srv) Extend(ctx context.Context, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	a, ok := s.auctions[id]
+
+	if !ok || !a.Extend(now, s.closingWindow, s.extension, s.maxExtension) {
+		return false
+	}
+
+	s.auctions[id] = a
+	s.log(id, Event{Kind: EventExtended, At: now.UTC(), EndsAt: &a.EndsAt})
+	log.Printf("lot %s extended to %s", id, a.EndsAt.Format(time.RFC3339))
+
+	// a lot pushed out of the window is open again until the next tick
+	s.transition(id, a.StatusAt(now, s.closingWindow), now)
+
+	return true
+}
+
+// Advance moves the scheduled lots to the state of the current time and returns the moves, oldest lot id first
+func (s *srv) Advance(ctx context.Context) []Transition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	ids := make([]string, 0, len(s.auctions))
+
+	for id := range s.auctions {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	var moves []Transition
+
+	for _, id := range ids {
+		if t, ok := s.transition(id, s.auctions[id].StatusAt(now, s.closingWindow), now); ok {
+			moves = append(moves, t)
+		}
+	}
+
+	return moves
+}
+
+// Run advances the lots every tick until ctx is done
+func (s *srv) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Advance(ctx)
+		}
+	}
+}
+
+// transition records the new status of the lot, it is false when the status did not change
+func (s *srv) transition(id, status string, now time.Time) (Transition, bool) {
+	from := s.statuses[id]
+
+	if from == status {
+		return Transition{}, false
+	}
+
+	s.statuses[id] = status
+	s.log(id, Event{Kind: EventMoved, At: now.UTC(), Status: status})
+	log.Printf("lot %s moved from %s to %s", id, from, status)
+
+	return Transition{LotID: id, From: from, To: status, At: now.UTC()}, true
+}
+
+// log appends the event to the lot log
+func (s *srv) log(id string, e Event) {
+	s.events[id] = append(s.events[id], e)
+}
+
+func (s *srv) state(a entity.Auction, now time.Time) *State {
+	st := &State{Auction: a, Status: a.StatusAt(now, s.closingWindow)}
+	st.Events = append([]Event(nil), s.events[a.LotID]...)
+
+	if a.Scheduled() && now.Before(a.EndsAt) {
+		st.Remaining = a.EndsAt.Sub(now)
+	}
+
+	return st
+}
@@ -0,0 +1,213 @@
+package lot_test
+
+import (
+	"context"
+	"maga-auctions/entity"
+	"maga-auctions/lot"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ctx   = context.Background()
+	start = time.Date(2020, 8, 28, 9, 0, 0, 0, time.UTC)
+	end   = start.Add(time.Hour)
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func newService(c *clock) lot.Service {
+	return lot.NewService(lot.WithClock(c.Now), lot.WithClosingWindow(10*time.Minute))
+}
+
+func TestSchedule(t *testing.T) {
+	c := &clock{now: start.Add(-time.Hour)}
+	srv := newService(c)
+
+	state, err := srv.Schedule(ctx, "0196", start, end)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.AuctionScheduled, state.Status)
+	assert.Equal(t, 2*time.Hour, state.Remaining)
+
+	c.now = start.Add(55 * time.Minute)
+	state, err = srv.ByID(ctx, "0196")
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.AuctionClosing, state.Status)
+	assert.Equal(t, 5*time.Minute, state.Remaining)
+}
+
+func TestSchedule_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, id, want   string
+		startsAt, endsAt time.Time
+	}{
+		{desc: "must return error when id is blank", id: " ", startsAt: start, endsAt: end, want: "invalid lot id"},
+		{desc: "must return error when the end is before the start", id: "0196", startsAt: end, endsAt: start, want: "lot must end after it starts"},
+		{desc: "must return error when the times are missing", id: "0196", want: "lot must end after it starts"},
+		{desc: "must return error when the lot is closed", id: "0033", startsAt: start, endsAt: end.Add(time.Hour), want: "lot 0033 is closed"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := &clock{now: start}
+			srv := newService(c)
+			_, _ = srv.Schedule(ctx, "0033", start, start.Add(time.Minute))
+			c.now = end
+
+			state, err := srv.Schedule(ctx, tt.id, tt.startsAt, tt.endsAt)
+
+			assert.Nil(t, state)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestByID(t *testing.T) {
+	t.Run("must keep the lots without schedule open", func(t *testing.T) {
+		srv := newService(&clock{now: start})
+
+		state, err := srv.ByID(ctx, "0196")
+
+		assert.Nil(t, err)
+		assert.Equal(t, &lot.State{Auction: entity.Auction{LotID: "0196"}, Status: entity.AuctionOpen}, state)
+	})
+
+	t.Run("must return error when id is blank", func(t *testing.T) {
+		srv := newService(&clock{now: start})
+
+		state, err := srv.ByID(ctx, "")
+
+		assert.Nil(t, state)
+		assert.EqualError(t, err, "invalid lot id")
+	})
+}
+
+func TestCanBid(t *testing.T) {
+	testCases := []struct {
+		desc, want string
+		now        time.Time
+	}{
+		{desc: "must refuse bids before the start", now: start.Add(-time.Second), want: "lot 0196 is scheduled"},
+		{desc: "must take bids while open", now: start},
+		{desc: "must take bids while closing", now: end.Add(-time.Minute)},
+		{desc: "must refuse bids after the end", now: end, want: "lot 0196 is closed"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := &clock{now: start.Add(-time.Hour)}
+			srv := newService(c)
+			_, _ = srv.Schedule(ctx, "0196", start, end)
+			c.now = tt.now
+
+			err := srv.CanBid(ctx, "0196")
+
+			if tt.want == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+
+	t.Run("must take bids on vehicles without lot", func(t *testing.T) {
+		assert.Nil(t, newService(&clock{now: start}).CanBid(ctx, ""))
+	})
+}
+
+func TestAdvance(t *testing.T) {
+	c := &clock{now: start.Add(-time.Hour)}
+	srv := newService(c)
+	_, _ = srv.Schedule(ctx, "0196", start, end)
+	_, _ = srv.Schedule(ctx, "0033", start, end.Add(time.Hour))
+
+	assert.Empty(t, srv.Advance(ctx))
+
+	c.now = end.Add(-5 * time.Minute)
+	assert.Equal(t, []lot.Transition{
+		{LotID: "0033", From: entity.AuctionScheduled, To: entity.AuctionOpen, At: c.now},
+		{LotID: "0196", From: entity.AuctionScheduled, To: entity.AuctionClosing, At: c.now},
+	}, srv.Advance(ctx))
+
+	c.now = end
+	assert.Equal(t, []lot.Transition{
+		{LotID: "0196", From: entity.AuctionClosing, To: entity.AuctionClosed, At: c.now},
+	}, srv.Advance(ctx))
+	assert.Empty(t, srv.Advance(ctx))
+}
+
+func TestRun(t *testing.T) {
+	c := &clock{now: start}
+	srv := lot.NewService(lot.WithClock(c.Now))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		srv.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
+
+func TestExtend(t *testing.T) {
+	c := &clock{now: start}
+	srv := lot.NewService(lot.WithClock(c.Now), lot.WithClosingWindow(5*time.Minute), lot.WithSoftClose(3*time.Minute, 5*time.Minute))
+	_, _ = srv.Schedule(ctx, "0196", start, end)
+
+	assert.False(t, srv.Extend(ctx, "0196"), "must not extend while open")
+	assert.False(t, srv.Extend(ctx, "0033"), "must not extend a lot without schedule")
+
+	c.now = end.Add(-time.Minute)
+	srv.Advance(ctx)
+	assert.True(t, srv.Extend(ctx, "0196"))
+
+	state, _ := srv.ByID(ctx, "0196")
+	assert.Equal(t, end.Add(3*time.Minute), state.EndsAt)
+	assert.Equal(t, end, state.ScheduledEndAt)
+	assert.Equal(t, entity.AuctionClosing, state.Status)
+
+	c.now = end.Add(2 * time.Minute)
+	assert.True(t, srv.Extend(ctx, "0196"))
+	c.now = end.Add(4 * time.Minute)
+	assert.False(t, srv.Extend(ctx, "0196"), "must not extend past the cap")
+
+	state, _ = srv.ByID(ctx, "0196")
+	extended, capped := end.Add(3*time.Minute), end.Add(5*time.Minute)
+
+	assert.Equal(t, capped, state.EndsAt)
+	assert.Equal(t, []lot.Event{
+		{Kind: lot.EventScheduled, At: start, Status: entity.AuctionOpen, EndsAt: &end},
+		{Kind: lot.EventMoved, At: end.Add(-time.Minute), Status: entity.AuctionClosing},
+		{Kind: lot.EventExtended, At: end.Add(-time.Minute), EndsAt: &extended},
+		{Kind: lot.EventExtended, At: end.Add(2 * time.Minute), EndsAt: &capped},
+	}, state.Events)
+}
+
+func TestExtend_Reopens(t *testing.T) {
+	c := &clock{now: end.Add(-time.Minute)}
+	srv := lot.NewService(lot.WithClock(c.Now), lot.WithClosingWindow(2*time.Minute), lot.WithSoftClose(10*time.Minute, time.Hour))
+	_, _ = srv.Schedule(ctx, "0196", start, end)
+
+	assert.True(t, srv.Extend(ctx, "0196"))
+
+	state, _ := srv.ByID(ctx, "0196")
+	assert.Equal(t, entity.AuctionOpen, state.Status)
+	assert.Equal(t, lot.Event{Kind: lot.EventMoved, At: c.now, Status: entity.AuctionOpen}, state.Events[len(state.Events)-1])
+}
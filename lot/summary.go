@@ -0,0 +1,207 @@
+package lot
+
+import (
+	"context"
+	"log"
+	"maga-auctions/api/handler"
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/api/helper/paging"
+	"maga-auctions/api/helper/sorting"
+	"maga-auctions/entity"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VehicleCatalog lists every vehicle
+type VehicleCatalog interface {
+	All(ctx context.Context, filters []filters.Filter, order string) (*[]entity.Vehicle, error)
+}
+
+// Summary aggregates the current bids of the vehicles of a lot
+type Summary struct {
+	ID         string        `json:"id"`
+	Status     string        `json:"status"`
+	Vehicles   int           `json:"vehicles"`
+	HighestBid *entity.Money `json:"highestBid,omitempty"`
+	LowestBid  *entity.Money `json:"lowestBid,omitempty"`
+	LastBidAt  *time.Time    `json:"lastBidAt,omitempty"`
+	Bidders    int           `json:"bidders"`
+}
+
+// ListQuery selects a page of lots ordered by the fields of Sort, a leading - orders a field descending
+type ListQuery struct {
+	Sort string
+	Page paging.Page
+}
+
+// SummaryPage is a page of lots
+type SummaryPage struct {
+	Items    []Summary `json:"items"`
+	Page     int       `json:"page"`
+	PageSize int       `json:"pageSize"`
+	Total    int       `json:"total"`
+}
+
+// summaryOrders are the fields the lots can be sorted by
+var summaryOrders = sorting.Fields{
+	"id":         bySummary(func(a, b Summary) int { return strings.Compare(a.ID, b.ID) }),
+	"vehicles":   bySummary(func(a, b Summary) int { return sorting.Ints(a.Vehicles, b.Vehicles) }),
+	"highestBid": bySummary(func(a, b Summary) int { return compareMoney(a.HighestBid, b.HighestBid) }),
+	"lowestBid":  bySummary(func(a, b Summary) int { return compareMoney(a.LowestBid, b.LowestBid) }),
+	"lastBidAt":  bySummary(func(a, b Summary) int { return compareTime(a.LastBidAt, b.LastBidAt) }),
+	"bidders":    bySummary(func(a, b Summary) int { return sorting.Ints(a.Bidders, b.Bidders) }),
+}
+
+func bySummary(compare func(a, b Summary) int) sorting.Compare {
+	return func(a, b interface{}) int {
+		return compare(a.(Summary), b.(Summary))
+	}
+}
+
+func (s *srv) All(ctx context.Context, catalog VehicleCatalog, query ListQuery) (*SummaryPage, error) {
+	order := query.Sort
+
+	if strings.TrimSpace(order) == "" {
+		order = "id"
+	}
+
+	// the lots come out of a map, the id breaks the ties
+	cmp, err := summaryOrders.Build(order + ",id")
+
+	if err != nil {
+		return nil, handler.BadRequest{Message: "lots " + err.Error()}
+	}
+
+	page, err := paging.New(query.Page.Number, query.Page.Size)
+
+	if err != nil {
+		return nil, handler.BadRequest{Message: err.Error()}
+	}
+
+	vs, err := catalog.All(ctx, nil, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := s.summarize(*vs)
+
+	sort.SliceStable(items, func(i, j int) bool { return cmp.Less(items[i], items[j]) })
+
+	start, end := page.Bounds(len(items))
+
+	return &SummaryPage{
+		Items:    items[start:end],
+		Page:     page.Number,
+		PageSize: page.Size,
+		Total:    len(items),
+	}, nil
+}
+
+// summarize groups the vehicles by lot, the vehicles without a lot are left out
+func (s *srv) summarize(vs []entity.Vehicle) []Summary {
+	byID := map[string]*Summary{}
+	bidders := map[string]map[string]bool{}
+	items := []Summary{}
+
+	for _, v := range vs {
+		id := strings.TrimSpace(v.Lot.ID)
+
+		if id == "" {
+			continue
+		}
+
+		sum, ok := byID[id]
+
+		if !ok {
+			sum = &Summary{ID: id}
+			byID[id] = sum
+			bidders[id] = map[string]bool{}
+		}
+
+		sum.Vehicles++
+
+		if !v.Bid.Placed() {
+			continue
+		}
+
+		value, date := v.Bid.Value, v.Bid.Date
+
+		// the extremes are in the currency of the first bid of the lot, the others cannot be compared with them
+		if sum.HighestBid != nil && value.CurrencyCode() != sum.HighestBid.CurrencyCode() {
+			log.Printf("lot %s vehicle %d bid in %s left out of the bids in %s", id, v.ID, value.CurrencyCode(), sum.HighestBid.CurrencyCode())
+		} else {
+			if sum.HighestBid == nil || value.Cmp(*sum.HighestBid) > 0 {
+				sum.HighestBid = &value
+			}
+
+			if sum.LowestBid == nil || value.Cmp(*sum.LowestBid) < 0 {
+				sum.LowestBid = &value
+			}
+		}
+
+		if sum.LastBidAt == nil || date.After(*sum.LastBidAt) {
+			sum.LastBidAt = &date
+		}
+
+		bidders[id][v.Bid.User] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+
+	for id, sum := range byID {
+		a, ok := s.auctions[id]
+
+		if !ok {
+			a = entity.Auction{LotID: id}
+		}
+
+		sum.Status = a.StatusAt(now, s.closingWindow)
+		sum.Bidders = len(bidders[id])
+		items = append(items, *sum)
+	}
+
+	return items
+}
+
+// compareMoney orders the amounts, a missing amount comes first and the amounts of different currencies are grouped by the currency code
+func compareMoney(a, b *entity.Money) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	c, err := a.Compare(*b)
+
+	if err != nil {
+		return strings.Compare(a.CurrencyCode(), b.CurrencyCode())
+	}
+
+	return c
+}
+
+// compareTime orders the dates, a missing date comes first
+func compareTime(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case a.Before(*b):
+		return -1
+	case a.After(*b):
+		return 1
+	}
+
+	return 0
+}
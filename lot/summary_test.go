@@ -0,0 +1,117 @@
+package lot_test
+
+import (
+	"context"
+	"errors"
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/api/helper/paging"
+	"maga-auctions/entity"
+	"maga-auctions/lot"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type catalog struct {
+	vehicles []entity.Vehicle
+	err      error
+}
+
+func (c catalog) All(ctx context.Context, filters []filters.Filter, bidOrder string) (*[]entity.Vehicle, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &c.vehicles, nil
+}
+
+var lots = catalog{vehicles: []entity.Vehicle{
+	{ID: 1, Lot: entity.Lot{ID: "0161"}, Bid: entity.Bid{Date: start, Value: entity.NewMoney(550000, ""), User: "ana"}},
+	{ID: 2, Lot: entity.Lot{ID: "0161"}, Bid: entity.Bid{Date: start.Add(time.Hour), Value: entity.NewMoney(2250000, ""), User: "bia"}},
+	{ID: 3, Lot: entity.Lot{ID: "0161"}, Bid: entity.Bid{Date: start.Add(-time.Hour), Value: entity.NewMoney(100000, ""), User: "ana"}},
+	{ID: 4, Lot: entity.Lot{ID: "0033"}, Bid: entity.Bid{User: "-"}},
+	{ID: 5, Lot: entity.Lot{ID: "0196"}, Bid: entity.Bid{Date: start, Value: entity.NewMoney(900000, ""), User: "caio"}},
+	{ID: 6, Bid: entity.Bid{Date: start, Value: entity.NewMoney(900000, ""), User: "caio"}},
+}}
+
+func TestAll(t *testing.T) {
+	c := &clock{now: start}
+	srv := newService(c)
+	_, _ = srv.Schedule(ctx, "0196", start.Add(time.Hour), end.Add(time.Hour))
+
+	money := func(amount int64) *entity.Money {
+		m := entity.NewMoney(amount, "")
+		return &m
+	}
+	date := func(d time.Time) *time.Time { return &d }
+
+	l0033 := lot.Summary{ID: "0033", Status: entity.AuctionOpen, Vehicles: 1}
+	l0161 := lot.Summary{ID: "0161", Status: entity.AuctionOpen, Vehicles: 3, HighestBid: money(2250000), LowestBid: money(100000), LastBidAt: date(start.Add(time.Hour)), Bidders: 2}
+	l0196 := lot.Summary{ID: "0196", Status: entity.AuctionScheduled, Vehicles: 1, HighestBid: money(900000), LowestBid: money(900000), LastBidAt: date(start), Bidders: 1}
+
+	testCases := []struct {
+		desc  string
+		query lot.ListQuery
+		want  []lot.Summary
+	}{
+		{desc: "must list the lots by id", want: []lot.Summary{l0033, l0161, l0196}},
+		{desc: "must sort the lots descending", query: lot.ListQuery{Sort: "-vehicles"}, want: []lot.Summary{l0161, l0033, l0196}},
+		{desc: "must sort the lots without bids first", query: lot.ListQuery{Sort: "highestBid"}, want: []lot.Summary{l0033, l0196, l0161}},
+		{desc: "must sort the lots by the last bid", query: lot.ListQuery{Sort: "-lastBidAt"}, want: []lot.Summary{l0161, l0196, l0033}},
+		{desc: "must break the ties with the next fields", query: lot.ListQuery{Sort: "vehicles,-id"}, want: []lot.Summary{l0196, l0033, l0161}},
+		{desc: "must page the lots", query: lot.ListQuery{Sort: "-bidders", Page: paging.Page{Number: 2, Size: 2}}, want: []lot.Summary{l0033}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			page, err := srv.All(ctx, lots, tt.query)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, page.Items)
+			assert.Equal(t, 3, page.Total)
+		})
+	}
+}
+
+func TestAll_Currencies(t *testing.T) {
+	usd := func(amount int64) *entity.Money {
+		m := entity.NewMoney(amount, "USD")
+		return &m
+	}
+	mixed := catalog{vehicles: []entity.Vehicle{
+		{ID: 1, Lot: entity.Lot{ID: "0161"}, Bid: entity.Bid{Date: start, Value: *usd(100), User: "ana"}},
+		{ID: 2, Lot: entity.Lot{ID: "0161"}, Bid: entity.Bid{Date: start, Value: entity.NewMoney(2250000, ""), User: "bia"}},
+		{ID: 3, Lot: entity.Lot{ID: "0161"}, Bid: entity.Bid{Date: start, Value: *usd(50), User: "caio"}},
+		{ID: 4, Lot: entity.Lot{ID: "0196"}, Bid: entity.Bid{Date: start, Value: entity.NewMoney(900000, ""), User: "caio"}},
+	}}
+
+	page, err := newService(&clock{now: start}).All(ctx, mixed, lot.ListQuery{Sort: "-highestBid"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"0161", "0196"}, []string{page.Items[0].ID, page.Items[1].ID}, "must group the currencies instead of comparing their amounts")
+	assert.Equal(t, usd(100), page.Items[0].HighestBid, "must leave the bids in another currency out of the extremes")
+	assert.Equal(t, usd(50), page.Items[0].LowestBid)
+	assert.Equal(t, 3, page.Items[0].Bidders)
+}
+
+func TestAll_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, want string
+		query      lot.ListQuery
+		catalog    catalog
+	}{
+		{desc: "must return error when the sort field is unknown", query: lot.ListQuery{Sort: "-brand"}, catalog: lots, want: `lots cannot be sorted by "brand"`},
+		{desc: "must return error when the page is invalid", query: lot.ListQuery{Page: paging.Page{Number: -1}}, catalog: lots, want: "page is invalid"},
+		{desc: "must return error when the vehicles cannot be listed", catalog: catalog{err: errors.New("legacy api is down")}, want: "legacy api is down"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			page, err := newService(&clock{now: start}).All(ctx, tt.catalog, tt.query)
+
+			assert.Nil(t, page)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package proxy
+
+import (
+	"context"
+	"log"
+	"maga-auctions/entity"
+	"maga-auctions/legacy"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reasons of the audit entries
+const (
+	ReasonMax = "max" // the user set a maximum
+	ReasonBid = "bid" // the engine bid for the user
+)
+
+// Engine bids for the users up to their maximums
+type Engine interface {
+	SetMax(vehicleID int, user string, max entity.Money) error
+	Respond(ctx context.Context, vehicle *entity.Vehicle) ([]entity.Bid, error)
+	Audit(vehicleID int) []Entry
+}
+
+// Entry of the audit log
+type Entry struct {
+	VehicleID int          `json:"vehicleId"`
+	User      string       `json:"user"`
+	Value     entity.Money `json:"value"`
+	Date      time.Time    `json:"date"`
+	Reason    string       `json:"reason"`
+}
+
+type max struct {
+	user  string
+	value entity.Money
+	seq   int
+}
+
+type engine struct {
+	legacyAPI legacy.API
+	increment entity.Money
+	now       func() time.Time
+
+	mu    sync.Mutex
+	seq   int
+	maxes map[int]map[string]max
+	audit map[int][]Entry
+}
+
+// Option configures the engine
+type Option func(*engine)
+
+// WithClock sets the clock that dates the bids and the audit log
+func WithClock(now func() time.Time) Option {
+	return func(e *engine) {
+		e.now = now
+	}
+}
+
+// NewEngine returns an engine that raises the bids by increment and pushes them through api
+func NewEngine(api legacy.API, increment entity.Money, opts ...Option) Engine {
+	e := &engine{
+		legacyAPI: api,
+		increment: increment,
+		now:       time.Now,
+		maxes:     map[int]map[string]max{},
+		audit:     map[int][]Entry{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// SetMax stores the maximum of the user, a new maximum replaces the previous one and loses its seniority
+func (e *engine) SetMax(vehicleID int, user string, value entity.Money) error {
+	if _, err := value.Sub(e.increment); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.maxes[vehicleID] == nil {
+		e.maxes[vehicleID] = map[string]max{}
+	}
+
+	e.seq++
+	e.maxes[vehicleID][user] = max{user: user, value: value, seq: e.seq}
+	e.log(Entry{VehicleID: vehicleID, User: user, Value: value, Date: e.date(), Reason: ReasonMax})
+
+	return nil
+}
+
+// Respond answers the standing bid of the vehicle with the proxies of the other users.
+// Competing proxies are settled at once: the runner-up bids its maximum and the strongest
+// proxy beats it by the increment, capped at its own maximum. The top bid is pushed
+// through the legacy api and set on the vehicle.
+func (e *engine) Respond(ctx context.Context, vehicle *entity.Vehicle) ([]entity.Bid, error) {
+	e.mu.Lock()
+	bids := e.resolve(vehicle.ID, vehicle.Bid)
+	e.mu.Unlock()
+
+	if len(bids) == 0 {
+		return nil, nil
+	}
+
+	updated := *vehicle
+	updated.Bid = bids[len(bids)-1]
+
+	if err := e.legacyAPI.Update(ctx, &updated); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	for _, b := range bids {
+		e.log(Entry{VehicleID: vehicle.ID, User: b.User, Value: b.Value, Date: b.Date, Reason: ReasonBid})
+	}
+	e.mu.Unlock()
+
+	*vehicle = updated
+
+	return bids, nil
+}
+
+// Audit returns a copy of the audit log of the vehicle, oldest first
+func (e *engine) Audit(vehicleID int) []Entry {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entries := make([]Entry, len(e.audit[vehicleID]))
+	copy(entries, e.audit[vehicleID])
+
+	return entries
+}
+
+// resolve returns the bids the proxies give against the standing bid, the last one is the top bid
+func (e *engine) resolve(vehicleID int, standing entity.Bid) []entity.Bid {
+	step := e.step()
+	next, err := standing.Value.Add(step)
+
+	if err != nil || !standing.Placed() {
+		next = step
+	}
+
+	var candidates []max
+
+	for _, m := range e.maxes[vehicleID] {
+		if m.user == standing.User && m.value.Cmp(standing.Value) > 0 {
+			candidates = append(candidates, m)
+			continue
+		}
+
+		if m.user != standing.User && m.value.Cmp(next) >= 0 {
+			candidates = append(candidates, m)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if c := candidates[i].value.Cmp(candidates[j].value); c != 0 {
+			return c > 0
+		}
+
+		return candidates[i].seq < candidates[j].seq
+	})
+
+	if len(candidates) == 0 || (len(candidates) == 1 && candidates[0].user == standing.User) {
+		return nil
+	}
+
+	date := e.date()
+	winner := candidates[0]
+	price := next
+	var bids []entity.Bid
+
+	if len(candidates) > 1 {
+		runnerUp := candidates[1]
+		bids = append(bids, entity.Bid{Date: date, Value: runnerUp.value, User: runnerUp.user})
+		price, _ = runnerUp.value.Add(step)
+	}
+
+	if price.Cmp(winner.value) > 0 {
+		price = winner.value
+	}
+
+	return append(bids, entity.Bid{Date: date, Value: price, User: winner.user})
+}
+
+// step is the least raise of a bid, one cent when no increment is set
+func (e *engine) step() entity.Money {
+	if e.increment.IsZero() {
+		return entity.NewMoney(1, e.increment.Currency)
+	}
+
+	return e.increment
+}
+
+// date of the generated bids, the legacy api keeps it to the minute
+func (e *engine) date() time.Time {
+	return e.now().UTC().Truncate(time.Minute)
+}
+
+func (e *engine) log(entry Entry) {
+	e.audit[entry.VehicleID] = append(e.audit[entry.VehicleID], entry)
+	log.Printf("proxy %s vehicle %d user %s value %s", entry.Reason, entry.VehicleID, entry.User, entry.Value)
+}
@@ -0,0 +1,194 @@
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"maga-auctions/entity"
+	mock_legacy "maga-auctions/legacy/mocks"
+	"maga-auctions/proxy"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ctx       = context.Background()
+	now       = time.Date(2020, 8, 28, 9, 30, 42, 0, time.UTC)
+	date      = now.Truncate(time.Minute)
+	clock     = proxy.WithClock(func() time.Time { return now })
+	increment = entity.NewMoney(10000, "")
+)
+
+func reais(v int64) entity.Money {
+	return entity.NewMoney(v*100, "")
+}
+
+type maxBid struct {
+	user  string
+	value int64
+}
+
+func TestRespond(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		maxes    []maxBid
+		standing entity.Bid
+		want     []entity.Bid
+	}{
+		{
+			desc:     "must beat the standing bid by the increment",
+			maxes:    []maxBid{{"ana", 1500}},
+			standing: entity.Bid{User: "bia", Value: reais(1000)},
+			want:     []entity.Bid{{User: "ana", Value: reais(1100), Date: date}},
+		},
+		{
+			desc:     "must open the bidding with the increment",
+			maxes:    []maxBid{{"ana", 1500}},
+			standing: entity.Bid{User: "-"},
+			want:     []entity.Bid{{User: "ana", Value: reais(100), Date: date}},
+		},
+		{
+			desc:     "must settle competing proxies until the runner-up is exhausted",
+			maxes:    []maxBid{{"ana", 1500}, {"bia", 1300}},
+			standing: entity.Bid{User: "caio", Value: reais(1000)},
+			want: []entity.Bid{
+				{User: "bia", Value: reais(1300), Date: date},
+				{User: "ana", Value: reais(1400), Date: date},
+			},
+		},
+		{
+			desc:     "must cap the top bid at the winner max",
+			maxes:    []maxBid{{"ana", 1500}, {"bia", 1450}},
+			standing: entity.Bid{User: "caio", Value: reais(1000)},
+			want: []entity.Bid{
+				{User: "bia", Value: reais(1450), Date: date},
+				{User: "ana", Value: reais(1500), Date: date},
+			},
+		},
+		{
+			desc:     "must give a tie to the oldest max",
+			maxes:    []maxBid{{"ana", 1500}, {"bia", 1500}},
+			standing: entity.Bid{User: "caio", Value: reais(1000)},
+			want: []entity.Bid{
+				{User: "bia", Value: reais(1500), Date: date},
+				{User: "ana", Value: reais(1500), Date: date},
+			},
+		},
+		{
+			desc:     "must defend the leader against a competing proxy",
+			maxes:    []maxBid{{"ana", 1500}, {"bia", 1200}},
+			standing: entity.Bid{User: "ana", Value: reais(1000)},
+			want: []entity.Bid{
+				{User: "bia", Value: reais(1200), Date: date},
+				{User: "ana", Value: reais(1300), Date: date},
+			},
+		},
+		{
+			desc:     "must let the leader proxy raise before being outbid",
+			maxes:    []maxBid{{"ana", 1200}, {"bia", 1500}},
+			standing: entity.Bid{User: "ana", Value: reais(1000)},
+			want: []entity.Bid{
+				{User: "ana", Value: reais(1200), Date: date},
+				{User: "bia", Value: reais(1300), Date: date},
+			},
+		},
+		{
+			desc:     "must not bid against the leader itself",
+			maxes:    []maxBid{{"ana", 1500}},
+			standing: entity.Bid{User: "ana", Value: reais(1000)},
+		},
+		{
+			desc:     "must not bid when the proxies are exhausted",
+			maxes:    []maxBid{{"ana", 1050}},
+			standing: entity.Bid{User: "bia", Value: reais(1000)},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			e := proxy.NewEngine(api, increment, clock)
+
+			for _, m := range tt.maxes {
+				assert.Nil(t, e.SetMax(760, m.user, reais(m.value)))
+			}
+
+			vehicle := entity.Vehicle{ID: 760, Bid: tt.standing}
+
+			if len(tt.want) > 0 {
+				api.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, v *entity.Vehicle) error {
+					assert.Equal(t, tt.want[len(tt.want)-1], v.Bid)
+					return nil
+				})
+			}
+
+			bids, err := e.Respond(ctx, &vehicle)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, bids)
+
+			if len(tt.want) > 0 {
+				assert.Equal(t, tt.want[len(tt.want)-1], vehicle.Bid)
+			} else {
+				assert.Equal(t, tt.standing, vehicle.Bid)
+			}
+		})
+	}
+}
+
+func TestRespond_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("legacy api error"))
+
+	e := proxy.NewEngine(api, increment, clock)
+	assert.Nil(t, e.SetMax(760, "ana", reais(1500)))
+
+	standing := entity.Bid{User: "bia", Value: reais(1000)}
+	vehicle := entity.Vehicle{ID: 760, Bid: standing}
+
+	bids, err := e.Respond(ctx, &vehicle)
+
+	assert.EqualError(t, err, "legacy api error")
+	assert.Nil(t, bids)
+	assert.Equal(t, standing, vehicle.Bid)
+	assert.Len(t, e.Audit(760), 1)
+}
+
+func TestSetMax_Errors(t *testing.T) {
+	e := proxy.NewEngine(nil, increment, clock)
+
+	assert.Equal(t, entity.ErrCurrencyMismatch, e.SetMax(760, "ana", entity.NewMoney(150000, "USD")))
+	assert.Empty(t, e.Audit(760))
+}
+
+func TestAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	e := proxy.NewEngine(api, increment, clock)
+	assert.Nil(t, e.SetMax(760, "ana", reais(1500)))
+	assert.Nil(t, e.SetMax(760, "bia", reais(1300)))
+
+	vehicle := entity.Vehicle{ID: 760, Bid: entity.Bid{User: "caio", Value: reais(1000)}}
+	_, err := e.Respond(ctx, &vehicle)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []proxy.Entry{
+		{VehicleID: 760, User: "ana", Value: reais(1500), Date: date, Reason: proxy.ReasonMax},
+		{VehicleID: 760, User: "bia", Value: reais(1300), Date: date, Reason: proxy.ReasonMax},
+		{VehicleID: 760, User: "bia", Value: reais(1300), Date: date, Reason: proxy.ReasonBid},
+		{VehicleID: 760, User: "ana", Value: reais(1400), Date: date, Reason: proxy.ReasonBid},
+	}, e.Audit(760))
+	assert.Empty(t, e.Audit(761))
+}
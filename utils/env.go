@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 	"gopkg.in/yaml.v2"
@@ -21,13 +22,40 @@ func init() {
 // Config contains the mapping of environment variables
 type Config struct {
 	API struct {
-		Env  string `yaml:"env", envconfig:"API_ENV"`
-		Port string `yaml:"port", envconfig:"API_PORT"`
+		Env         string `yaml:"env" envconfig:"ENV"`
+		Port        string `yaml:"port" envconfig:"PORT"`
+		PageSize    int    `yaml:"pageSize" envconfig:"PAGE_SIZE"`
+		MaxPageSize int    `yaml:"maxPageSize" envconfig:"MAX_PAGE_SIZE"`
 	} `yaml:"api"`
 
 	Legacy struct {
-		URI string `yaml:"uri", envconfig:"LEGACY_URI"`
+		URI            string `yaml:"uri" envconfig:"URI"`
+		InvalidBidDate string `yaml:"invalidBidDate" envconfig:"INVALID_BID_DATE"`
+		Cache          struct {
+			TTL time.Duration `yaml:"ttl" envconfig:"TTL"`
+		} `yaml:"cache"`
+		Retry struct {
+			MaxAttempts      int           `yaml:"maxAttempts" envconfig:"MAX_ATTEMPTS"`
+			Backoff          time.Duration `yaml:"backoff" envconfig:"BACKOFF"`
+			MaxBackoff       time.Duration `yaml:"maxBackoff" envconfig:"MAX_BACKOFF"`
+			Jitter           float64       `yaml:"jitter" envconfig:"JITTER"`
+			Status           []int         `yaml:"status" envconfig:"STATUS"`
+			IdempotentCreate bool          `yaml:"idempotentCreate" envconfig:"IDEMPOTENT_CREATE"`
+		} `yaml:"retry"`
+		Breaker struct {
+			FailureThreshold int           `yaml:"failureThreshold" envconfig:"FAILURE_THRESHOLD"`
+			SuccessThreshold int           `yaml:"successThreshold" envconfig:"SUCCESS_THRESHOLD"`
+			CoolDown         time.Duration `yaml:"coolDown" envconfig:"COOL_DOWN"`
+		} `yaml:"breaker"`
 	} `yaml:"legacy"`
+
+	Auction struct {
+		MinIncrement      string        `yaml:"minIncrement" envconfig:"MIN_INCREMENT"`
+		ClosingWindow     time.Duration `yaml:"closingWindow" envconfig:"CLOSING_WINDOW"`
+		SchedulerInterval time.Duration `yaml:"schedulerInterval" envconfig:"SCHEDULER_INTERVAL"`
+		Extension         time.Duration `yaml:"extension" envconfig:"EXTENSION"`
+		MaxExtension      time.Duration `yaml:"maxExtension" envconfig:"MAX_EXTENSION"`
+	} `yaml:"auction"`
 }
 
 func processError(err error) {
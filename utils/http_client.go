@@ -2,7 +2,9 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 )
 
@@ -11,11 +13,15 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-func MakeRequest(method, uri string, body interface{}) (*http.Request, error) {
+// MakeRequest builds a json request bound to ctx
+func MakeRequest(ctx context.Context, method, uri string, body interface{}) (*http.Request, error) {
 	b, err := json.Marshal(body)
-	payload := bytes.NewReader(b)
 
-	req, err := http.NewRequest(method, uri, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewReader(b))
 
 	if err != nil {
 		return nil, err
@@ -25,3 +31,25 @@ func MakeRequest(method, uri string, body interface{}) (*http.Request, error) {
 
 	return req, nil
 }
+
+type contextReader struct {
+	ctx context.Context
+	rc  io.ReadCloser
+}
+
+// NewContextReader stops reading rc as soon as ctx is done
+func NewContextReader(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	return &contextReader{ctx: ctx, rc: rc}
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.rc.Read(p)
+}
+
+func (r *contextReader) Close() error {
+	return r.rc.Close()
+}
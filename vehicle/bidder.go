@@ -0,0 +1,80 @@
+package vehicle
+
+import (
+	"context"
+	"maga-auctions/api/handler"
+	"maga-auctions/api/helper/filters"
+	"maga-auctions/entity"
+	"sort"
+	"strings"
+)
+
+// UserBids are the vehicles where the user holds the top bid, grouped by lot.
+// Total sums the bids in the default currency and OtherTotals the others, one per currency
+type UserBids struct {
+	User        string         `json:"user"`
+	Vehicles    int            `json:"vehicles"`
+	Total       entity.Money   `json:"total"`
+	OtherTotals []entity.Money `json:"otherTotals,omitempty"`
+	Lots        []LotBids      `json:"lots"`
+}
+
+// LotBids are the vehicles of a lot where the user holds the top bid
+type LotBids struct {
+	LotID       string           `json:"lotId"`
+	Vehicles    []entity.Vehicle `json:"vehicles"`
+	Total       entity.Money     `json:"total"`
+	OtherTotals []entity.Money   `json:"otherTotals,omitempty"`
+}
+
+func (s srv) BidsByUser(ctx context.Context, user string) (*UserBids, error) {
+	if strings.TrimSpace(user) == "" {
+		return nil, handler.BadRequest{Message: "invalid user"}
+	}
+
+	items, err := s.All(ctx, []filters.Filter{filters.NewVehicleBidder(user)}, "lot.id,id")
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &UserBids{
+		User:  strings.TrimSpace(user),
+		Total: entity.NewMoney(0, entity.DefaultCurrency),
+		Lots:  []LotBids{},
+	}
+
+	for _, v := range *items {
+		if n := len(res.Lots); n == 0 || res.Lots[n-1].LotID != v.Lot.ID {
+			res.Lots = append(res.Lots, LotBids{LotID: v.Lot.ID, Total: entity.NewMoney(0, entity.DefaultCurrency)})
+		}
+
+		lot := &res.Lots[len(res.Lots)-1]
+		lot.Vehicles = append(lot.Vehicles, v)
+
+		lot.Total, lot.OtherTotals = addBid(lot.Total, lot.OtherTotals, v.Bid.Value)
+		res.Total, res.OtherTotals = addBid(res.Total, res.OtherTotals, v.Bid.Value)
+		res.Vehicles++
+	}
+
+	return res, nil
+}
+
+// addBid sums value into total when they share the currency, or into the total of its currency in others kept by code
+func addBid(total entity.Money, others []entity.Money, value entity.Money) (entity.Money, []entity.Money) {
+	if sum, err := total.Add(value); err == nil {
+		return sum, others
+	}
+
+	for i, o := range others {
+		if sum, err := o.Add(value); err == nil {
+			others[i] = sum
+			return total, others
+		}
+	}
+
+	others = append(others, value)
+	sort.Slice(others, func(i, j int) bool { return others[i].CurrencyCode() < others[j].CurrencyCode() })
+
+	return total, others
+}
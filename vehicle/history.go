@@ -0,0 +1,62 @@
+package vehicle
+
+import (
+	"maga-auctions/entity"
+	"sort"
+	"sync"
+)
+
+// History keeps the bids seen for each vehicle
+type History interface {
+	Record(id int, bid entity.Bid) bool
+	Bids(id int) []entity.Bid
+}
+
+type memoryHistory struct {
+	mu   sync.RWMutex
+	bids map[int][]entity.Bid
+}
+
+// NewHistory returns a history kept in memory
+func NewHistory() History {
+	return &memoryHistory{
+		bids: map[int][]entity.Bid{},
+	}
+}
+
+// Record adds the bid in date order, it is false when the bid was not placed or is already known
+func (h *memoryHistory) Record(id int, bid entity.Bid) bool {
+	if !bid.Placed() {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bids := h.bids[id]
+
+	for _, b := range bids {
+		if b.Equal(bid) {
+			return false
+		}
+	}
+
+	i := sort.Search(len(bids), func(i int) bool { return bids[i].Date.After(bid.Date) })
+	bids = append(bids, entity.Bid{})
+	copy(bids[i+1:], bids[i:])
+	bids[i] = bid
+	h.bids[id] = bids
+
+	return true
+}
+
+// Bids returns a copy of the bids of the vehicle, oldest first
+func (h *memoryHistory) Bids(id int) []entity.Bid {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	bids := make([]entity.Bid, len(h.bids[id]))
+	copy(bids, h.bids[id])
+
+	return bids
+}
@@ -0,0 +1,51 @@
+package vehicle_test
+
+import (
+	"maga-auctions/entity"
+	"maga-auctions/vehicle"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	first := entity.Bid{User: "ana", Value: entity.NewMoney(100000, ""), Date: time.Date(2020, 8, 21, 10, 0, 0, 0, time.UTC)}
+	second := entity.Bid{User: "bia", Value: entity.NewMoney(110000, ""), Date: first.Date.Add(time.Hour)}
+	third := entity.Bid{User: "ana", Value: entity.NewMoney(120000, ""), Date: second.Date.Add(time.Hour)}
+
+	t.Run("must keep the bids in date order", func(t *testing.T) {
+		h := vehicle.NewHistory()
+
+		assert.True(t, h.Record(1, third))
+		assert.True(t, h.Record(1, first))
+		assert.True(t, h.Record(1, second))
+		assert.Equal(t, []entity.Bid{first, second, third}, h.Bids(1))
+		assert.Empty(t, h.Bids(2))
+	})
+
+	t.Run("must ignore the known bids", func(t *testing.T) {
+		h := vehicle.NewHistory()
+
+		assert.True(t, h.Record(1, first))
+		assert.False(t, h.Record(1, first))
+		assert.Len(t, h.Bids(1), 1)
+	})
+
+	t.Run("must ignore the vehicles without bids", func(t *testing.T) {
+		h := vehicle.NewHistory()
+
+		assert.False(t, h.Record(1, entity.Bid{User: "-"}))
+		assert.Empty(t, h.Bids(1))
+	})
+
+	t.Run("must return a copy", func(t *testing.T) {
+		h := vehicle.NewHistory()
+		h.Record(1, first)
+
+		bids := h.Bids(1)
+		bids[0].User = "changed"
+
+		assert.Equal(t, "ana", h.Bids(1)[0].User)
+	})
+}
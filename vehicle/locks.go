@@ -0,0 +1,29 @@
+package vehicle
+
+import "sync"
+
+// locks serializes the read-modify-write of each vehicle
+type locks struct {
+	mu   sync.Mutex
+	byID map[int]*sync.Mutex
+}
+
+func newLocks() *locks {
+	return &locks{byID: map[int]*sync.Mutex{}}
+}
+
+// lock holds the vehicle until the returned func is called
+func (l *locks) lock(id int) func() {
+	l.mu.Lock()
+	m, ok := l.byID[id]
+
+	if !ok {
+		m = &sync.Mutex{}
+		l.byID[id] = m
+	}
+
+	l.mu.Unlock()
+	m.Lock()
+
+	return m.Unlock
+}
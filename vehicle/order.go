@@ -0,0 +1,44 @@
+package vehicle
+
+import (
+	"maga-auctions/api/handler"
+	"maga-auctions/api/helper/sorting"
+	"maga-auctions/entity"
+	"sort"
+)
+
+// vehicleOrders are the fields the vehicles can be sorted by
+var vehicleOrders = sorting.Fields{
+	"id":                byVehicle(func(a, b entity.Vehicle) int { return sorting.Ints(a.ID, b.ID) }),
+	"brand":             byVehicle(func(a, b entity.Vehicle) int { return sorting.Strings(a.Brand, b.Brand) }),
+	"model":             byVehicle(func(a, b entity.Vehicle) int { return sorting.Strings(a.Model, b.Model) }),
+	"modelYear":         byVehicle(func(a, b entity.Vehicle) int { return sorting.Ints(a.ModelYear, b.ModelYear) }),
+	"manufacturingYear": byVehicle(func(a, b entity.Vehicle) int { return sorting.Ints(a.ManufacturingYear, b.ManufacturingYear) }),
+	"lot.id":            byVehicle(func(a, b entity.Vehicle) int { return sorting.Strings(a.Lot.ID, b.Lot.ID) }),
+	"lot.vehicleLotId":  byVehicle(func(a, b entity.Vehicle) int { return sorting.Strings(a.Lot.VehicleLotID, b.Lot.VehicleLotID) }),
+	"bid.date":          byVehicle(func(a, b entity.Vehicle) int { return sorting.Times(a.Bid.Date, b.Bid.Date) }),
+	"bid.value":         byVehicle(func(a, b entity.Vehicle) int { return a.Bid.Value.Cmp(b.Bid.Value) }),
+	"bid.user":          byVehicle(func(a, b entity.Vehicle) int { return sorting.Strings(a.Bid.User, b.Bid.User) }),
+}
+
+func byVehicle(compare func(a, b entity.Vehicle) int) sorting.Compare {
+	return func(a, b interface{}) int {
+		return compare(a.(entity.Vehicle), b.(entity.Vehicle))
+	}
+}
+
+// vehicleOrder builds the comparator of the sort, an unknown field is a bad request
+func vehicleOrder(order string) (sorting.Comparator, error) {
+	cmp, err := vehicleOrders.Build(order)
+
+	if err != nil {
+		return nil, handler.BadRequest{Message: "vehicles " + err.Error()}
+	}
+
+	return cmp, nil
+}
+
+// sortVehicles orders the vehicles keeping the legacy order of the ties
+func sortVehicles(items []entity.Vehicle, cmp sorting.Comparator) {
+	sort.SliceStable(items, func(i, j int) bool { return cmp.Less(items[i], items[j]) })
+}
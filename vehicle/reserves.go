@@ -0,0 +1,47 @@
+package vehicle
+
+import (
+	"maga-auctions/entity"
+	"sync"
+)
+
+// Reserves keeps the reserve price of each vehicle, the legacy api has no field for it
+type Reserves interface {
+	Set(id int, price entity.Money)
+	Get(id int) (entity.Money, bool)
+}
+
+type memoryReserves struct {
+	mu     sync.RWMutex
+	prices map[int]entity.Money
+}
+
+// NewReserves returns reserves kept in memory
+func NewReserves() Reserves {
+	return &memoryReserves{
+		prices: map[int]entity.Money{},
+	}
+}
+
+// Set keeps the reserve price of the vehicle, a zero price removes it
+func (r *memoryReserves) Set(id int, price entity.Money) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if price.IsZero() {
+		delete(r.prices, id)
+		return
+	}
+
+	r.prices[id] = price
+}
+
+// Get returns the reserve price of the vehicle
+func (r *memoryReserves) Get(id int) (entity.Money, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	price, ok := r.prices[id]
+
+	return price, ok
+}
@@ -0,0 +1,34 @@
+package vehicle_test
+
+import (
+	"maga-auctions/entity"
+	"maga-auctions/vehicle"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserves(t *testing.T) {
+	t.Run("must keep the reserve price", func(t *testing.T) {
+		r := vehicle.NewReserves()
+		r.Set(1, entity.NewMoney(100000, ""))
+
+		price, ok := r.Get(1)
+
+		assert.True(t, ok)
+		assert.Equal(t, entity.NewMoney(100000, ""), price)
+
+		_, ok = r.Get(2)
+		assert.False(t, ok)
+	})
+
+	t.Run("must remove the reserve price when it is zero", func(t *testing.T) {
+		r := vehicle.NewReserves()
+		r.Set(1, entity.NewMoney(100000, ""))
+		r.Set(1, entity.Money{})
+
+		_, ok := r.Get(1)
+
+		assert.False(t, ok)
+	})
+}
@@ -1,52 +1,174 @@
 package vehicle
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"maga-auctions/api/handler"
 	"maga-auctions/api/helper/filters"
+	"maga-auctions/api/helper/paging"
 	"maga-auctions/entity"
 	"maga-auctions/legacy"
-	"sort"
+	"maga-auctions/lot"
+	"maga-auctions/proxy"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"context"
 )
 
 // Service contract
 type Service interface {
-	All(ctx context.Context, filters []filters.Filter, bidOrder string) (*[]entity.Vehicle, error)
+	All(ctx context.Context, filters []filters.Filter, order string) (*[]entity.Vehicle, error)
 	ByID(ctx context.Context, id int) (*entity.Vehicle, error)
-	ByLotID(ctx context.Context, lotID, bidOrder string) (*[]entity.Vehicle, error)
+	ByLotID(ctx context.Context, lotID, order string) (*[]entity.Vehicle, error)
+	ByVehicleLotID(ctx context.Context, lotID, vehicleLotID string) (*entity.Vehicle, error)
+	List(ctx context.Context, filters []filters.Filter, order string, query PageQuery) (*VehiclePage, error)
+	ListByLot(ctx context.Context, lotID, order string, query PageQuery) (*VehiclePage, error)
 	Create(ctx context.Context, vehicle entity.Vehicle) (*entity.Vehicle, error)
-	Update(ctx context.Context, vehicle *entity.Vehicle) error
-	Delete(ctx context.Context, id int) error
+	Update(ctx context.Context, vehicle *entity.Vehicle, version string) error
+	Delete(ctx context.Context, id int, version string) error
+	Bids(ctx context.Context, id int, query BidQuery) (*BidPage, error)
+	PlaceBid(ctx context.Context, id int, user string, value entity.Money) (*entity.Vehicle, error)
+	SetMaxBid(ctx context.Context, id int, user string, max entity.Money) (*entity.Vehicle, error)
+	BidsByUser(ctx context.Context, user string) (*UserBids, error)
+}
+
+// BidQuery selects a page of the bid history, zero dates leave the range open
+type BidQuery struct {
+	From, To time.Time
+	Page     paging.Page
+}
+
+// BidPage is a page of the bid history
+type BidPage struct {
+	Items    []entity.Bid `json:"items"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+	Total    int          `json:"total"`
+}
+
+// PageQuery selects a page of vehicles by its number or by the cursor of the page before
+type PageQuery struct {
+	Page   paging.Page
+	Cursor string
+}
+
+// VehiclePage is a page of vehicles, the page number is zero when it was selected by cursor
+type VehiclePage struct {
+	Items      []entity.Vehicle `json:"items"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page,omitempty"`
+	PageSize   int              `json:"pageSize"`
+	NextCursor string           `json:"nextCursor,omitempty"`
 }
 
 type srv struct {
-	legacyAPI legacy.API
+	legacyAPI    legacy.API
+	history      History
+	reserves     Reserves
+	minIncrement entity.Money
+	now          func() time.Time
+	locks        *locks
+	unique       *sync.Mutex
+	proxy        proxy.Engine
+	lots         lot.Service
+}
+
+// Option configures the service
+type Option func(*srv)
+
+// WithHistory sets where the bid history is kept
+func WithHistory(h History) Option {
+	return func(s *srv) {
+		s.history = h
+	}
+}
+
+// WithReserves sets where the reserve prices are kept
+func WithReserves(r Reserves) Option {
+	return func(s *srv) {
+		s.reserves = r
+	}
+}
+
+// WithMinIncrement sets how much a bid must beat the current one by
+func WithMinIncrement(m entity.Money) Option {
+	return func(s *srv) {
+		s.minIncrement = m
+	}
+}
+
+// WithClock sets the clock that dates the bids
+func WithClock(now func() time.Time) Option {
+	return func(s *srv) {
+		s.now = now
+	}
+}
+
+// WithProxy sets the engine that bids for the users up to their maximums
+func WithProxy(e proxy.Engine) Option {
+	return func(s *srv) {
+		s.proxy = e
+	}
+}
+
+// WithLots sets the service that tells whether the lot of a vehicle takes bids
+func WithLots(l lot.Service) Option {
+	return func(s *srv) {
+		s.lots = l
+	}
 }
 
 // NewService returns a planet service instance
-func NewService(api legacy.API) Service {
-	return &srv{
+func NewService(api legacy.API, opts ...Option) Service {
+	s := &srv{
 		legacyAPI: api,
+		history:   NewHistory(),
+		reserves:  NewReserves(),
+		now:       time.Now,
+		locks:     newLocks(),
+		unique:    &sync.Mutex{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.lots == nil {
+		s.lots = lot.NewService()
+	}
+
+	if s.proxy == nil {
+		s.proxy = proxy.NewEngine(api, s.minIncrement, proxy.WithClock(s.now))
 	}
+
+	return s
 }
 
-func (s srv) All(ctx context.Context, filters []filters.Filter, bidOrder string) (*[]entity.Vehicle, error) {
+func (s srv) All(ctx context.Context, filters []filters.Filter, order string) (*[]entity.Vehicle, error) {
+	cmp, err := vehicleOrder(order)
+
+	if err != nil {
+		return nil, err
+	}
+
 	items, err := s.legacyAPI.Get(ctx)
 
-	if err != nil || items == nil {
-		return nil, handler.InternalServer{Message: "error when searching for vehicles in legacy api"}
+	if err != nil {
+		return nil, legacyError(err, "error when searching for vehicles in legacy api")
 	}
 
-	if strings.TrimSpace(bidOrder) != "" {
-		if bidOrder == "asc" {
-			sort.Sort(entity.VehiclesAsc(items))
-		} else {
-			sort.Sort(entity.VehiclesDesc(items))
-		}
+	if items == nil {
+		return nil, handler.InternalServer{Message: "error when searching for vehicles in legacy api"}
 	}
 
+	s.observe(items)
+
+	sortVehicles(items, cmp)
+
 	for _, f := range filters {
 		f.Apply(&items)
 	}
@@ -59,31 +181,64 @@ func (s srv) ByID(ctx context.Context, id int) (*entity.Vehicle, error) {
 		return nil, handler.BadRequest{Message: "invalid id"}
 	}
 
+	// the cache keeps an index of its result, without it the list is scanned
+	if finder, ok := s.legacyAPI.(legacy.Finder); ok {
+		vehicle, err := finder.Find(ctx, id)
+
+		if err != nil {
+			return nil, legacyError(err, "error when searching for vehicles in legacy api")
+		}
+
+		s.history.Record(vehicle.ID, vehicle.Bid)
+		s.reserve(&vehicle)
+
+		return &vehicle, nil
+	}
+
 	items, err := s.legacyAPI.Get(ctx)
 
-	if err != nil || items == nil {
+	if err != nil {
+		return nil, legacyError(err, "error when searching for vehicles in legacy api")
+	}
+
+	if items == nil {
 		return nil, handler.InternalServer{Message: "error when searching for vehicles in legacy api"}
 	}
 
-	if id > len(items) {
-		return nil, handler.BadRequest{Message: "invalid id"}
+	s.observe(items)
+
+	for i := range items {
+		if items[i].ID == id {
+			return &items[i], nil
+		}
 	}
 
-	vehicle := items[id-1]
-	return &vehicle, nil
+	return nil, handler.NotFound{Message: "vehicle not found"}
 }
 
-func (s srv) ByLotID(ctx context.Context, lotID, bidOrder string) (*[]entity.Vehicle, error) {
+func (s srv) ByLotID(ctx context.Context, lotID, order string) (*[]entity.Vehicle, error) {
 	if strings.TrimSpace(lotID) == "" {
 		return nil, handler.BadRequest{Message: "invalid lot id"}
 	}
 
+	cmp, err := vehicleOrder(order)
+
+	if err != nil {
+		return nil, err
+	}
+
 	items, err := s.legacyAPI.Get(ctx)
 
-	if err != nil || items == nil {
+	if err != nil {
+		return nil, legacyError(err, "error when searching for vehicles in legacy api")
+	}
+
+	if items == nil {
 		return nil, handler.InternalServer{Message: "error when searching for vehicles in legacy api"}
 	}
 
+	s.observe(items)
+
 	var vehicles []entity.Vehicle
 
 	for _, v := range items {
@@ -93,57 +248,486 @@ func (s srv) ByLotID(ctx context.Context, lotID, bidOrder string) (*[]entity.Veh
 		}
 	}
 
-	if strings.TrimSpace(bidOrder) != "" {
-		if bidOrder == "asc" {
-			sort.Sort(entity.VehiclesAsc(vehicles))
-		} else {
-			sort.Sort(entity.VehiclesDesc(vehicles))
+	sortVehicles(vehicles, cmp)
+
+	return &vehicles, nil
+}
+
+func (s srv) List(ctx context.Context, filters []filters.Filter, order string, query PageQuery) (*VehiclePage, error) {
+	items, err := s.All(ctx, filters, order)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newVehiclePage(*items, query)
+}
+
+func (s srv) ListByLot(ctx context.Context, lotID, order string, query PageQuery) (*VehiclePage, error) {
+	items, err := s.ByLotID(ctx, lotID, order)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newVehiclePage(*items, query)
+}
+
+func (s srv) ByVehicleLotID(ctx context.Context, lotID, vehicleLotID string) (*entity.Vehicle, error) {
+	if strings.TrimSpace(vehicleLotID) == "" {
+		return nil, handler.BadRequest{Message: "invalid vehicle lot id"}
+	}
+
+	vs, err := s.ByLotID(ctx, lotID, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range *vs {
+		if v.Lot.VehicleLotID == vehicleLotID {
+			return &v, nil
 		}
 	}
 
-	return &vehicles, nil
+	return nil, handler.NotFound{Message: "vehicle not found"}
 }
 
 func (s srv) Create(ctx context.Context, vehicle entity.Vehicle) (*entity.Vehicle, error) {
+	// a frozen lot takes no new vehicle, and it does not close until the vehicle lands
+	release := s.lots.Hold(vehicle.Lot.ID)
+	defer release()
+
+	if err := s.lots.CanChange(ctx, vehicle.Lot.ID); err != nil {
+		return nil, err
+	}
+
+	s.unique.Lock()
+	defer s.unique.Unlock()
+
+	if err := s.checkUnique(ctx, vehicle); err != nil {
+		return nil, err
+	}
+
+	// a new vehicle has no bid, the bids only go through PlaceBid
+	vehicle.Bid = entity.Bid{}
+
 	err := s.legacyAPI.Create(ctx, &vehicle)
 
-	if err != nil || vehicle.ID == 0 {
-		return nil, handler.InternalServer{Message: err.Error()}
+	if err != nil {
+		return nil, legacyError(err, "error when creating the vehicle in legacy api")
+	}
+
+	if vehicle.ID == 0 {
+		return nil, handler.BadGateway{Message: "legacy api did not return the vehicle id"}
+	}
+
+	if vehicle.Reserve != nil {
+		s.reserves.Set(vehicle.ID, *vehicle.Reserve)
 	}
 
+	s.reserve(&vehicle)
+
 	return &vehicle, nil
 }
 
-func (s srv) Update(ctx context.Context, vehicle *entity.Vehicle) error {
+func (s srv) Update(ctx context.Context, vehicle *entity.Vehicle, version string) error {
 	if vehicle.ID <= 0 {
 		return handler.BadRequest{Message: "invalid id"}
 	}
 
-	if err := s.legacyAPI.Update(ctx, vehicle); err != nil {
-		msg := err.Error()
-		if msg == "id not found" {
-			return handler.BadRequest{Message: msg}
+	unlock := s.locks.lock(vehicle.ID)
+	defer unlock()
+
+	current, err := s.current(ctx, vehicle.ID, version)
+
+	if err != nil {
+		return err
+	}
+
+	// a frozen lot can neither lose the vehicle nor take it in, and neither lot closes until the write lands
+	release := s.lots.Hold(current.Lot.ID, vehicle.Lot.ID)
+	defer release()
+
+	if err := s.lots.CanChange(ctx, current.Lot.ID); err != nil {
+		return err
+	}
+
+	if vehicle.Lot.ID != current.Lot.ID {
+		if err := s.lots.CanChange(ctx, vehicle.Lot.ID); err != nil {
+			return err
 		}
+	}
+
+	s.unique.Lock()
+	defer s.unique.Unlock()
+
+	if err := s.checkUnique(ctx, *vehicle); err != nil {
+		return err
+	}
+
+	// the bids only go through PlaceBid, which checks the lot and the increment
+	vehicle.Bid = current.Bid
+
+	if err := s.legacyAPI.Update(ctx, vehicle); err != nil {
+		return legacyError(err, "error when updating the vehicle in legacy api")
+	}
 
-		return handler.InternalServer{Message: msg}
+	if vehicle.Reserve != nil {
+		s.reserves.Set(vehicle.ID, *vehicle.Reserve)
 	}
 
+	s.reserve(vehicle)
+
 	return nil
 }
 
-func (s srv) Delete(ctx context.Context, id int) error {
+func (s srv) Delete(ctx context.Context, id int, version string) error {
 	if id <= 0 {
 		return handler.BadRequest{Message: "invalid id"}
 	}
 
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	current, err := s.current(ctx, id, version)
+
+	if err != nil {
+		return err
+	}
+
+	release := s.lots.Hold(current.Lot.ID)
+	defer release()
+
+	if err := s.lots.CanChange(ctx, current.Lot.ID); err != nil {
+		return err
+	}
+
 	if err := s.legacyAPI.Delete(ctx, id); err != nil {
-		msg := err.Error()
-		if msg == "id not found" {
-			return handler.BadRequest{Message: msg}
+		return legacyError(err, "error when deleting the vehicle in legacy api")
+	}
+
+	s.reserves.Set(id, entity.Money{})
+
+	return nil
+}
+
+func (s srv) Bids(ctx context.Context, id int, query BidQuery) (*BidPage, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, handler.BadRequest{Message: "bid date to cannot be before from"}
+	}
+
+	if _, err := s.ByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	bids := []entity.Bid{}
+
+	for _, b := range s.history.Bids(id) {
+		if !query.From.IsZero() && b.Date.Before(query.From) {
+			continue
+		}
+
+		if !query.To.IsZero() && b.Date.After(query.To) {
+			continue
 		}
 
-		return handler.InternalServer{Message: msg}
+		bids = append(bids, b)
 	}
 
+	page, err := paging.New(query.Page.Number, query.Page.Size)
+
+	if err != nil {
+		return nil, handler.BadRequest{Message: err.Error()}
+	}
+
+	start, end := page.Bounds(len(bids))
+
+	return &BidPage{
+		Items:    bids[start:end],
+		Page:     page.Number,
+		PageSize: page.Size,
+		Total:    len(bids),
+	}, nil
+}
+
+func (s srv) PlaceBid(ctx context.Context, id int, user string, value entity.Money) (*entity.Vehicle, error) {
+	if strings.TrimSpace(user) == "" {
+		return nil, handler.BadRequest{Message: "bid user is required"}
+	}
+
+	if value.IsZero() || value.IsNegative() {
+		return nil, handler.BadRequest{Message: "bid value must be positive"}
+	}
+
+	// the legacy api keeps the bids in the default currency only
+	if value.CurrencyCode() != entity.DefaultCurrency {
+		return nil, handler.UnprocessableEntity{Message: fmt.Sprintf("bid value must be in %s", entity.DefaultCurrency)}
+	}
+
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	vehicle, err := s.ByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the lot cannot close between the check and the write of the bid
+	release := s.lots.Hold(vehicle.Lot.ID)
+	defer release()
+
+	if err := s.lots.CanBid(ctx, vehicle.Lot.ID); err != nil {
+		return nil, err
+	}
+
+	if vehicle.Bid.Placed() {
+		min, err := vehicle.Bid.Value.Add(s.minIncrement)
+
+		if err != nil {
+			return nil, handler.UnprocessableEntity{Message: err.Error()}
+		}
+
+		diff, err := value.Sub(min)
+
+		if err != nil {
+			return nil, handler.UnprocessableEntity{Message: err.Error()}
+		}
+
+		if diff.IsNegative() || value.Cmp(vehicle.Bid.Value) <= 0 {
+			return nil, handler.UnprocessableEntity{Message: fmt.Sprintf("bid must be greater than %s by at least %s", vehicle.Bid.Value, s.minIncrement)}
+		}
+	}
+
+	// the legacy api keeps the bid date to the minute
+	vehicle.Bid = entity.Bid{
+		Date:  s.now().UTC().Truncate(time.Minute),
+		Value: value,
+		User:  user,
+	}
+
+	if err := s.legacyAPI.Update(ctx, vehicle); err != nil {
+		return nil, legacyError(err, "error when placing the bid in legacy api")
+	}
+
+	s.accept(ctx, *vehicle, vehicle.Bid)
+
+	if err := s.respond(ctx, vehicle); err != nil {
+		return nil, err
+	}
+
+	s.reserve(vehicle)
+
+	return vehicle, nil
+}
+
+func (s srv) SetMaxBid(ctx context.Context, id int, user string, max entity.Money) (*entity.Vehicle, error) {
+	if strings.TrimSpace(user) == "" {
+		return nil, handler.BadRequest{Message: "bid user is required"}
+	}
+
+	if max.IsZero() || max.IsNegative() {
+		return nil, handler.BadRequest{Message: "max bid must be positive"}
+	}
+
+	if max.CurrencyCode() != entity.DefaultCurrency {
+		return nil, handler.UnprocessableEntity{Message: fmt.Sprintf("max bid must be in %s", entity.DefaultCurrency)}
+	}
+
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	vehicle, err := s.ByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the lot cannot close between the check and the write of the bid
+	release := s.lots.Hold(vehicle.Lot.ID)
+	defer release()
+
+	if err := s.lots.CanBid(ctx, vehicle.Lot.ID); err != nil {
+		return nil, err
+	}
+
+	min := vehicle.Bid.Value
+
+	if vehicle.Bid.Placed() && vehicle.Bid.User != user {
+		min, err = min.Add(s.minIncrement)
+
+		if err != nil {
+			return nil, handler.UnprocessableEntity{Message: err.Error()}
+		}
+	}
+
+	diff, err := max.Sub(min)
+
+	if err != nil {
+		return nil, handler.UnprocessableEntity{Message: err.Error()}
+	}
+
+	if diff.IsNegative() {
+		return nil, handler.UnprocessableEntity{Message: fmt.Sprintf("max bid must be at least %s", min)}
+	}
+
+	if err := s.proxy.SetMax(id, user, max); err != nil {
+		return nil, handler.UnprocessableEntity{Message: err.Error()}
+	}
+
+	if err := s.respond(ctx, vehicle); err != nil {
+		return nil, err
+	}
+
+	s.reserve(vehicle)
+
+	return vehicle, nil
+}
+
+// respond lets the proxies answer the standing bid and records the bids they gave
+func (s srv) respond(ctx context.Context, vehicle *entity.Vehicle) error {
+	bids, err := s.proxy.Respond(ctx, vehicle)
+
+	if err != nil {
+		return legacyError(err, "error when placing the proxy bids in legacy api")
+	}
+
+	s.accept(ctx, *vehicle, bids...)
+
 	return nil
 }
+
+// accept records the bids taken on the vehicle, a new bid while the lot is closing pushes its end out
+func (s srv) accept(ctx context.Context, vehicle entity.Vehicle, bids ...entity.Bid) {
+	taken := false
+
+	for _, b := range bids {
+		if s.history.Record(vehicle.ID, b) {
+			taken = true
+		}
+	}
+
+	if taken {
+		s.lots.Extend(ctx, vehicle.Lot.ID)
+	}
+}
+
+// current loads the stored vehicle and rejects the write when it changed since the version was read, an empty version skips the check
+func (s srv) current(ctx context.Context, id int, version string) (*entity.Vehicle, error) {
+	current, err := s.ByID(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if version != "" && current.Version() != version {
+		return nil, handler.PreconditionFailed{Message: "vehicle was changed, read it again before writing"}
+	}
+
+	return current, nil
+}
+
+// newVehiclePage cuts the page out of the vehicles, the cursor wins over the page number
+func newVehiclePage(items []entity.Vehicle, query PageQuery) (*VehiclePage, error) {
+	page, err := paging.New(query.Page.Number, query.Page.Size)
+
+	if err != nil {
+		return nil, handler.BadRequest{Message: err.Error()}
+	}
+
+	keyOf := func(i int) string { return strconv.Itoa(items[i].ID) }
+	start, end := page.Bounds(len(items))
+	res := &VehiclePage{Total: len(items), Page: page.Number, PageSize: page.Size}
+
+	if query.Cursor != "" {
+		if query.Page.Number > 1 {
+			return nil, handler.BadRequest{Message: "page and cursor cannot be used together"}
+		}
+
+		cursor, err := paging.DecodeCursor(query.Cursor)
+
+		if err != nil {
+			return nil, handler.BadRequest{Message: err.Error()}
+		}
+
+		start = cursor.Start(len(items), keyOf)
+		end = start + page.Size
+
+		if end > len(items) {
+			end = len(items)
+		}
+
+		res.Page = 0
+	}
+
+	res.Items = append([]entity.Vehicle{}, items[start:end]...)
+
+	if end < len(items) && end > 0 {
+		res.NextCursor = paging.Cursor{Offset: end, Key: keyOf(end - 1)}.Encode()
+	}
+
+	return res, nil
+}
+
+// checkUnique rejects the vehicle when another one of its lot has the same control code
+func (s srv) checkUnique(ctx context.Context, vehicle entity.Vehicle) error {
+	if strings.TrimSpace(vehicle.Lot.VehicleLotID) == "" {
+		return nil
+	}
+
+	items, err := s.legacyAPI.Get(ctx)
+
+	if err != nil {
+		return legacyError(err, "error when searching for vehicles in legacy api")
+	}
+
+	for _, v := range items {
+		if v.ID != vehicle.ID && v.Lot.ID == vehicle.Lot.ID && v.Lot.VehicleLotID == vehicle.Lot.VehicleLotID {
+			return handler.Conflict{Message: fmt.Sprintf("vehicle lot id %s is already in lot %s", vehicle.Lot.VehicleLotID, vehicle.Lot.ID)}
+		}
+	}
+
+	return nil
+}
+
+// observe records the bids that changed since the last legacy api read and checks them against the reserves
+func (s srv) observe(items []entity.Vehicle) {
+	for i := range items {
+		s.history.Record(items[i].ID, items[i].Bid)
+		s.reserve(&items[i])
+	}
+}
+
+// reserve loads the reserve price of the vehicle and whether its bid meets it
+func (s srv) reserve(vehicle *entity.Vehicle) {
+	vehicle.Reserve = nil
+
+	if price, ok := s.reserves.Get(vehicle.ID); ok {
+		vehicle.Reserve = &price
+	}
+
+	vehicle.ReserveMet = vehicle.MeetsReserve()
+}
+
+// legacyError maps the errors of the legacy api to http errors
+func legacyError(err error, message string) error {
+	var upstream legacy.ErrUpstreamStatus
+	var decode legacy.ErrDecode
+
+	switch {
+	case errors.Is(err, legacy.ErrNotFound):
+		return handler.NotFound{Message: "vehicle not found"}
+	case errors.Is(err, legacy.ErrTimeout):
+		return handler.GatewayTimeout{Message: message}
+	case errors.Is(err, legacy.ErrCircuitOpen):
+		return handler.ServiceUnavailable{Message: err.Error()}
+	case errors.Is(err, legacy.ErrCurrency):
+		return handler.UnprocessableEntity{Message: err.Error()}
+	case errors.As(err, &upstream), errors.As(err, &decode):
+		log.Print(err)
+		return handler.BadGateway{Message: message}
+	default:
+		return handler.InternalServer{Message: fmt.Sprintf("%s: %v", message, err)}
+	}
+}
@@ -4,26 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"maga-auctions/api/handler"
 	"maga-auctions/api/helper/filters"
+	"maga-auctions/api/helper/paging"
 	"maga-auctions/entity"
 	"maga-auctions/legacy"
 	mock_legacy "maga-auctions/legacy/mocks"
+	"maga-auctions/lot"
 	"maga-auctions/utils"
 	"maga-auctions/vehicle"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
-	ctx, cancel   = context.WithTimeout(context.Background(), 2*time.Second)
+	ctx           = context.Background()
 	vehicleLegacy = legacy.VehicleLegacy{
 		ID:             1,
 		DataLance:      "21/08/2020 - 11:24",
 		Lote:           "0033",
-		CodigoControle: "80623",
+		CodigoControle: "80699",
 		Marca:          "Marca Teste",
 		Modelo:         "Modelo Teste",
 		AnoFabricacao:  2011,
@@ -59,19 +67,23 @@ func TestAll(t *testing.T) {
 	}{
 		{
 			desc:  "must return list of vehicle asc",
-			order: "asc",
+			order: "bid.date",
 			want:  20,
 		},
 		{
-			desc:  "must return list of vehicle asc",
-			order: "desc",
+			desc:  "must return list of vehicle desc",
+			order: "-bid.date",
+			want:  20,
+		},
+		{
+			desc:  "must return list of vehicle by many fields",
+			order: "-bid.value,brand,modelYear",
 			want:  20,
 		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
 			mockApiLegacy("testdata/consultar_response_api.json", 200)
 
 			api := legacy.NewAPI()
@@ -81,17 +93,16 @@ func TestAll(t *testing.T) {
 				filters.NewVehicleModel("S"),
 			}
 
-			resp, _ := srv.All(ctx, filters, tt.order)
+			resp, err := srv.All(ctx, filters, tt.order)
 
-			assert.NotNil(t, resp)
-			assert.Len(t, *resp, 20)
+			assert.Nil(t, err)
+			assert.Len(t, *resp, tt.want)
 		})
 	}
 }
 
 func TestAll_Errors(t *testing.T) {
 	t.Run("return error when searching for vehicles in legacy api", func(t *testing.T) {
-		defer cancel()
 		mockApiLegacy("", 500)
 
 		api := legacy.NewAPI()
@@ -101,13 +112,12 @@ func TestAll_Errors(t *testing.T) {
 
 		assert.Nil(t, item)
 		fmt.Print(err.Error())
-		assert.EqualError(t, err, "internal server error")
+		assert.EqualError(t, err, "error when searching for vehicles in legacy api")
 	})
 }
 
 func TestByID(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
-		defer cancel()
 		mockApiLegacy("testdata/consultar_response_api.json", 200)
 
 		api := legacy.NewAPI()
@@ -126,6 +136,43 @@ func TestByID(t *testing.T) {
 	})
 }
 
+func TestByID_Gaps(t *testing.T) {
+	t.Run("must find the vehicle by id when the legacy list has gaps", func(t *testing.T) {
+		mockApiLegacy("../legacy/testdata/consultar_response_api.json", 200)
+
+		api := legacy.NewAPI()
+		srv := vehicle.NewService(api)
+
+		item, err := srv.ByID(ctx, 306)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 306, item.ID)
+		assert.Equal(t, "0336", item.Lot.ID)
+	})
+}
+
+func TestByID_Cache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{{ID: 760, Brand: "IVECO"}, {ID: 761, Brand: "FIAT"}}, nil).Times(1)
+
+	srv := vehicle.NewService(legacy.NewCache(api, time.Minute))
+
+	for _, id := range []int{761, 760} {
+		item, err := srv.ByID(ctx, id)
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, item.ID, "must look the vehicle up in the index of the cache")
+	}
+
+	item, err := srv.ByID(ctx, 762)
+
+	assert.Nil(t, item)
+	assert.EqualError(t, err, "vehicle not found")
+}
+
 func TestByID_Errors(t *testing.T) {
 	testCases := []struct {
 		desc, jsonPATH, want    string
@@ -144,21 +191,27 @@ func TestByID_Errors(t *testing.T) {
 			id:                  1,
 			legacyApiStatusCode: 500,
 			err:                 errors.New("legacy api error"),
-			want:                "internal server error",
+			want:                "error when searching for vehicles in legacy api",
 		},
 		{
-			desc:                "must return error when id greater than zero",
+			desc:                "must return error when id is not found",
 			id:                  9999,
 			legacyApiStatusCode: 200,
 			jsonPATH:            "testdata/consultar_response_api.json",
-			want:                "invalid id",
+			want:                "vehicle not found",
 			items:               []legacy.VehicleLegacy{},
 		},
+		{
+			desc:                "must return error when the legacy row was dropped",
+			id:                  305,
+			legacyApiStatusCode: 200,
+			jsonPATH:            "../legacy/testdata/consultar_response_api.json",
+			want:                "vehicle not found",
+		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
 			mockApiLegacy(tt.jsonPATH, tt.legacyApiStatusCode)
 
 			api := legacy.NewAPI()
@@ -173,10 +226,24 @@ func TestByID_Errors(t *testing.T) {
 	}
 }
 
+// mockApiLegacyWrite answers the write with pathJSON and the read that checks the control codes with the dataset
+func mockApiLegacyWrite(pathJSON string, statusCode int) {
+	legacy.APIURI = "https://test.com"
+	legacy.Client = &mock_legacy.MockClient{}
+	mock_legacy.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+		b, _ := ioutil.ReadAll(req.Body)
+
+		if strings.Contains(string(b), `"OPERACAO":"consultar"`) {
+			return &http.Response{Body: utils.TestMakeBody("testdata/consultar_response_api.json"), StatusCode: 200}, nil
+		}
+
+		return &http.Response{Body: utils.TestMakeBody(pathJSON), StatusCode: statusCode}, nil
+	}
+}
+
 func TestCreate(t *testing.T) {
 	t.Run("must create vehicle", func(t *testing.T) {
-		defer cancel()
-		mockApiLegacy("testdata/criar_response_api.json", 200)
+		mockApiLegacyWrite("testdata/criar_response_api.json", 200)
 
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
@@ -196,8 +263,7 @@ func TestCreate(t *testing.T) {
 
 func TestCreate_Error(t *testing.T) {
 	t.Run("must return error when legacy API fails", func(t *testing.T) {
-		defer cancel()
-		mockApiLegacy("", 500)
+		mockApiLegacyWrite("", 500)
 
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
@@ -205,19 +271,18 @@ func TestCreate_Error(t *testing.T) {
 		item, err := srv.Create(ctx, v)
 
 		assert.Nil(t, item)
-		assert.EqualError(t, err, "internal server error")
+		assert.EqualError(t, err, "error when creating the vehicle in legacy api")
 	})
 }
 
 func TestUpdate(t *testing.T) {
 	t.Run("must update vehicle", func(t *testing.T) {
-		defer cancel()
-		mockApiLegacy("testdata/alterar_response_api.json", 200)
+		mockApiLegacyWrite("testdata/alterar_response_api.json", 200)
 
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
 
-		err := srv.Update(ctx, &v)
+		err := srv.Update(ctx, &v, "")
 
 		assert.Nil(t, err)
 	})
@@ -242,27 +307,26 @@ func TestUpdate_Errors(t *testing.T) {
 			jsonPATH:            "testdata/alterar_response_error_api.json",
 			legacyApiStatusCode: 200,
 			err:                 errors.New("id not found"),
-			want:                "id not found",
+			want:                "vehicle not found",
 		},
 		{
 			desc:                "must return error when an unknown error occurs in legacy api",
 			id:                  2,
 			legacyApiStatusCode: 500,
 			err:                 errors.New("error when updating in legacy api"),
-			want:                "internal server error",
+			want:                "error when updating the vehicle in legacy api",
 		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
-			mockApiLegacy(tt.jsonPATH, tt.legacyApiStatusCode)
+			mockApiLegacyWrite(tt.jsonPATH, tt.legacyApiStatusCode)
 
 			api := legacy.NewAPI()
 			srv := vehicle.NewService(api)
 
 			v.ID = tt.id
-			err := srv.Update(ctx, &v)
+			err := srv.Update(ctx, &v, "")
 
 			assert.NotNil(t, err)
 			assert.EqualError(t, err, tt.want)
@@ -272,13 +336,12 @@ func TestUpdate_Errors(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	t.Run("must delete vehicle", func(t *testing.T) {
-		defer cancel()
-		mockApiLegacy("testdata/apagar_response_api.json", 200)
+		mockApiLegacyWrite("testdata/apagar_response_api.json", 200)
 
 		api := legacy.NewAPI()
 		srv := vehicle.NewService(api)
 
-		err := srv.Delete(ctx, 1)
+		err := srv.Delete(ctx, 1, "")
 
 		assert.Nil(t, err)
 	})
@@ -303,27 +366,26 @@ func TestDelete_Errors(t *testing.T) {
 			legacyApiStatusCode: 200,
 			jsonPATH:            "testdata/apagar_response_error_api.json",
 			err:                 errors.New("id not found"),
-			want:                "id not found",
+			want:                "vehicle not found",
 		},
 		{
 			desc:                "must return error when an unknown error occurs in legacy api",
 			id:                  2,
 			legacyApiStatusCode: 500,
-			err:                 errors.New("error when updating in legacy api"),
-			want:                "internal server error",
+			err:                 errors.New("error when deleting in legacy api"),
+			want:                "error when deleting the vehicle in legacy api",
 		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
-			mockApiLegacy(tt.jsonPATH, tt.legacyApiStatusCode)
+			mockApiLegacyWrite(tt.jsonPATH, tt.legacyApiStatusCode)
 
 			api := legacy.NewAPI()
 			srv := vehicle.NewService(api)
 
 			v.ID = tt.id
-			err := srv.Delete(ctx, tt.id)
+			err := srv.Delete(ctx, tt.id, "")
 
 			assert.NotNil(t, err)
 			assert.EqualError(t, err, tt.want)
@@ -331,23 +393,68 @@ func TestDelete_Errors(t *testing.T) {
 	}
 }
 
+func TestAll_Order(t *testing.T) {
+	testCases := []struct {
+		desc, order string
+		want        []int
+	}{
+		{desc: "must order by the bid value and break the ties by brand", order: "-bid.value,brand", want: []int{3, 1, 2}},
+		{desc: "must order by brand and the model year descending", order: "brand,-modelYear", want: []int{2, 1, 3}},
+		{desc: "must keep the legacy order of the ties", order: "bid.value", want: []int{1, 2, 3}},
+		{desc: "must keep the legacy order without a sort", want: []int{1, 2, 3}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{
+				{ID: 1, Brand: "fiat", ModelYear: 2010, Bid: entity.Bid{Value: entity.NewMoney(100, "")}},
+				{ID: 2, Brand: "FIAT", ModelYear: 2012, Bid: entity.Bid{Value: entity.NewMoney(100, "")}},
+				{ID: 3, Brand: "honda", ModelYear: 2015, Bid: entity.Bid{Value: entity.NewMoney(900, "")}},
+			}, nil)
+
+			resp, err := vehicle.NewService(api).All(ctx, nil, tt.order)
+
+			assert.Nil(t, err)
+
+			ids := []int{}
+			for _, v := range *resp {
+				ids = append(ids, v.ID)
+			}
+
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestAll_OrderErrors(t *testing.T) {
+	t.Run("must return error when the field cannot be sorted", func(t *testing.T) {
+		resp, err := vehicle.NewService(legacy.NewAPI()).All(ctx, nil, "-bid.value,color")
+
+		assert.Nil(t, resp)
+		assert.EqualError(t, err, `vehicles cannot be sorted by "color"`)
+	})
+}
+
 func TestByLotID(t *testing.T) {
 	testCases := []struct {
 		desc, order string
 	}{
 		{
 			desc:  "must return list of vehicle asc",
-			order: "asc",
+			order: "bid.date",
 		},
 		{
-			desc:  "must return list of vehicle asc",
-			order: "desc",
+			desc:  "must return list of vehicle desc",
+			order: "-bid.date",
 		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
 			mockApiLegacy("testdata/consultar_response_api.json", 200)
 
 			api := legacy.NewAPI()
@@ -359,7 +466,7 @@ func TestByLotID(t *testing.T) {
 
 			items := *resp
 
-			if tt.order == "asc" {
+			if tt.order == "bid.date" {
 				assert.True(t, items[0].Bid.Date.Before(items[1].Bid.Date))
 			} else {
 				assert.True(t, items[0].Bid.Date.After(items[1].Bid.Date))
@@ -381,13 +488,12 @@ func TestByLotID_Errors(t *testing.T) {
 			desc:     "must return error when invalid lot id",
 			lotID:    "0161",
 			jsonPATH: "testdata/consultar_response_error_api.json",
-			want:     "internal server error",
+			want:     "error when searching for vehicles in legacy api",
 		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.desc, func(t *testing.T) {
-			defer cancel()
 			mockApiLegacy(tt.jsonPATH, 200)
 
 			api := legacy.NewAPI()
@@ -400,3 +506,913 @@ func TestByLotID_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeout(t *testing.T) {
+	t.Run("must return gateway timeout when the deadline expires", func(t *testing.T) {
+		c, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		api := legacy.NewAPI()
+		srv := vehicle.NewService(api)
+
+		_, err := srv.ByID(c, 1)
+
+		assert.IsType(t, handler.GatewayTimeout{}, err)
+	})
+}
+
+func TestBids(t *testing.T) {
+	seen := entity.Bid{
+		Date:  time.Date(2020, 8, 27, 10, 20, 0, 0, time.UTC),
+		Value: entity.NewMoney(7500000, ""),
+		User:  "ALDOBARROSO",
+	}
+	raised := entity.Bid{
+		Date:  seen.Date.Add(time.Hour),
+		Value: entity.NewMoney(8000000, ""),
+		User:  "ALLBARBOS",
+	}
+
+	newService := func(t *testing.T) vehicle.Service {
+		api := legacy.NewAPI()
+		srv := vehicle.NewService(api, vehicle.WithClock(func() time.Time { return raised.Date }))
+
+		mockApiLegacyWrite("testdata/alterar_response_api.json", 200)
+		_, err := srv.PlaceBid(ctx, 760, raised.User, raised.Value)
+		assert.Nil(t, err)
+
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		return srv
+	}
+
+	testCases := []struct {
+		desc  string
+		query vehicle.BidQuery
+		want  []entity.Bid
+		total int
+	}{
+		{
+			desc:  "must return the bids seen in the legacy api and placed, oldest first",
+			want:  []entity.Bid{seen, raised},
+			total: 2,
+		},
+		{
+			desc:  "must filter by date range",
+			query: vehicle.BidQuery{From: raised.Date},
+			want:  []entity.Bid{raised},
+			total: 1,
+		},
+		{
+			desc:  "must filter by date range closed on both sides",
+			query: vehicle.BidQuery{From: seen.Date.Add(-time.Hour), To: seen.Date},
+			want:  []entity.Bid{seen},
+			total: 1,
+		},
+		{
+			desc:  "must paginate",
+			query: vehicle.BidQuery{Page: paging.Page{Number: 2, Size: 1}},
+			want:  []entity.Bid{raised},
+			total: 2,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			srv := newService(t)
+
+			page, err := srv.Bids(ctx, 760, tt.query)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, page.Items)
+			assert.Equal(t, tt.total, page.Total)
+		})
+	}
+}
+
+func TestBids_Errors(t *testing.T) {
+	at := time.Date(2020, 8, 27, 10, 20, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc, want string
+		id         int
+		query      vehicle.BidQuery
+	}{
+		{
+			desc:  "must return error when the range is inverted",
+			id:    760,
+			query: vehicle.BidQuery{From: at, To: at.Add(-time.Hour)},
+			want:  "bid date to cannot be before from",
+		},
+		{
+			desc: "must return error when vehicle is not found",
+			id:   9999,
+			want: "vehicle not found",
+		},
+		{
+			desc:  "must return error when page is invalid",
+			id:    760,
+			query: vehicle.BidQuery{Page: paging.Page{Size: paging.MaxSize + 1}},
+			want:  "page size is invalid",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			srv := vehicle.NewService(legacy.NewAPI())
+
+			page, err := srv.Bids(ctx, tt.id, tt.query)
+
+			assert.Nil(t, page)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestPlaceBid(t *testing.T) {
+	now := time.Date(2020, 8, 28, 9, 30, 42, 0, time.UTC)
+	current := entity.Vehicle{
+		ID:  760,
+		Bid: entity.Bid{Date: now.Add(-time.Hour), Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, nil)
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, v *entity.Vehicle) error {
+		assert.Equal(t, "ALLBARBOS", v.Bid.User)
+		return nil
+	})
+
+	srv := vehicle.NewService(
+		api,
+		vehicle.WithMinIncrement(entity.NewMoney(10000, "")),
+		vehicle.WithClock(func() time.Time { return now }),
+	)
+
+	item, err := srv.PlaceBid(ctx, 760, "ALLBARBOS", entity.NewMoney(7510000, ""))
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Bid{Date: now.Truncate(time.Minute), Value: entity.NewMoney(7510000, ""), User: "ALLBARBOS"}, item.Bid)
+
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{*item}, nil)
+
+	page, err := srv.Bids(ctx, 760, vehicle.BidQuery{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.Bid{current.Bid, item.Bid}, page.Items)
+}
+
+func TestPlaceBid_Errors(t *testing.T) {
+	current := entity.Vehicle{
+		ID:  760,
+		Bid: entity.Bid{Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"},
+	}
+
+	testCases := []struct {
+		desc, user, want string
+		id               int
+		value            entity.Money
+		getErr, err      error
+	}{
+		{
+			desc:  "must return error when user is blank",
+			id:    760,
+			user:  " ",
+			value: entity.NewMoney(8000000, ""),
+			want:  "bid user is required",
+		},
+		{
+			desc:  "must return error when value is not positive",
+			id:    760,
+			user:  "ALLBARBOS",
+			value: entity.NewMoney(-1, ""),
+			want:  "bid value must be positive",
+		},
+		{
+			desc:  "must return error when vehicle is not found",
+			id:    9999,
+			user:  "ALLBARBOS",
+			value: entity.NewMoney(8000000, ""),
+			want:  "vehicle not found",
+		},
+		{
+			desc:  "must return error when bid does not beat the increment",
+			id:    760,
+			user:  "ALLBARBOS",
+			value: entity.NewMoney(7509999, ""),
+			want:  "bid must be greater than 75000.00 by at least 100.00",
+		},
+		{
+			desc:  "must return error when the bid is not in the default currency",
+			id:    760,
+			user:  "ALLBARBOS",
+			value: entity.NewMoney(8000000, "USD"),
+			want:  "bid value must be in BRL",
+		},
+		{
+			desc:   "must return error when legacy API fails",
+			id:     760,
+			user:   "ALLBARBOS",
+			value:  entity.NewMoney(8000000, ""),
+			getErr: legacy.ErrUpstreamStatus{Code: 500},
+			want:   "error when searching for vehicles in legacy api",
+		},
+		{
+			desc:  "must return error when legacy API rejects the bid",
+			id:    760,
+			user:  "ALLBARBOS",
+			value: entity.NewMoney(8000000, ""),
+			err:   legacy.ErrUpstreamStatus{Code: 500},
+			want:  "error when placing the bid in legacy api",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, tt.getErr).AnyTimes()
+
+			if tt.err != nil {
+				api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(tt.err)
+			}
+
+			srv := vehicle.NewService(api, vehicle.WithMinIncrement(entity.NewMoney(10000, "")))
+
+			item, err := srv.PlaceBid(ctx, tt.id, tt.user, tt.value)
+
+			assert.Nil(t, item)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestPlaceBid_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	current := entity.Vehicle{ID: 760, Bid: entity.Bid{Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).DoAndReturn(func(context.Context) ([]entity.Vehicle, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return []entity.Vehicle{current}, nil
+	}).AnyTimes()
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, v *entity.Vehicle) error {
+		mu.Lock()
+		defer mu.Unlock()
+		current = *v
+		return nil
+	}).AnyTimes()
+
+	srv := vehicle.NewService(api)
+
+	var wg sync.WaitGroup
+	var accepted int32
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := srv.PlaceBid(ctx, 760, "ALLBARBOS", entity.NewMoney(8000000, "")); err == nil {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), accepted)
+}
+
+func TestVersion(t *testing.T) {
+	current := entity.Vehicle{ID: 760, Brand: "IVECO"}
+
+	testCases := []struct {
+		desc, version, want string
+		write               bool
+	}{
+		{desc: "must write when the version matches", version: current.Version(), write: true},
+		{desc: "must write without a version", write: true},
+		{desc: "must reject a stale version", version: "0000000000000000", want: "vehicle was changed, read it again before writing"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, nil).AnyTimes()
+
+			if tt.write {
+				api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+				api.EXPECT().Delete(gomock.Any(), 760).Return(nil)
+			}
+
+			srv := vehicle.NewService(api)
+			updated := current
+			errUpdate := srv.Update(ctx, &updated, tt.version)
+			errDelete := srv.Delete(ctx, 760, tt.version)
+
+			if tt.want != "" {
+				assert.EqualError(t, errUpdate, tt.want)
+				assert.EqualError(t, errDelete, tt.want)
+				return
+			}
+
+			assert.Nil(t, errUpdate)
+			assert.Nil(t, errDelete)
+		})
+	}
+}
+
+func TestSetMaxBid(t *testing.T) {
+	now := time.Date(2020, 8, 28, 9, 30, 42, 0, time.UTC)
+	date := now.Truncate(time.Minute)
+	current := entity.Vehicle{ID: 760, Bid: entity.Bid{Date: now.Add(-time.Hour), Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"}}
+	opening := current.Bid
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).DoAndReturn(func(context.Context) ([]entity.Vehicle, error) {
+		return []entity.Vehicle{current}, nil
+	}).AnyTimes()
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, v *entity.Vehicle) error {
+		current = *v
+		return nil
+	}).Times(3)
+
+	srv := vehicle.NewService(
+		api,
+		vehicle.WithMinIncrement(entity.NewMoney(10000, "")),
+		vehicle.WithClock(func() time.Time { return now }),
+	)
+
+	item, err := srv.SetMaxBid(ctx, 760, "ALLBARBOS", entity.NewMoney(8000000, ""))
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Bid{Date: date, Value: entity.NewMoney(7510000, ""), User: "ALLBARBOS"}, item.Bid)
+
+	item, err = srv.PlaceBid(ctx, 760, "ALDOBARROSO", entity.NewMoney(7600000, ""))
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Bid{Date: date, Value: entity.NewMoney(7610000, ""), User: "ALLBARBOS"}, item.Bid)
+	assert.Equal(t, item.Bid, current.Bid)
+
+	page, err := srv.Bids(ctx, 760, vehicle.BidQuery{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.Bid{
+		opening,
+		{Date: date, Value: entity.NewMoney(7510000, ""), User: "ALLBARBOS"},
+		{Date: date, Value: entity.NewMoney(7600000, ""), User: "ALDOBARROSO"},
+		{Date: date, Value: entity.NewMoney(7610000, ""), User: "ALLBARBOS"},
+	}, page.Items)
+}
+
+func TestSetMaxBid_Errors(t *testing.T) {
+	current := entity.Vehicle{
+		ID:  760,
+		Bid: entity.Bid{Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"},
+	}
+
+	testCases := []struct {
+		desc, user, want string
+		max              entity.Money
+	}{
+		{
+			desc: "must return error when user is blank",
+			max:  entity.NewMoney(8000000, ""),
+			want: "bid user is required",
+		},
+		{
+			desc: "must return error when max is not positive",
+			user: "ALLBARBOS",
+			want: "max bid must be positive",
+		},
+		{
+			desc: "must return error when max does not beat the increment",
+			user: "ALLBARBOS",
+			max:  entity.NewMoney(7505000, ""),
+			want: "max bid must be at least 75100.00",
+		},
+		{
+			desc: "must return error when the leader max is below the standing bid",
+			user: "ALDOBARROSO",
+			max:  entity.NewMoney(7400000, ""),
+			want: "max bid must be at least 75000.00",
+		},
+		{
+			desc: "must return error when the max bid is not in the default currency",
+			user: "ALLBARBOS",
+			max:  entity.NewMoney(8000000, "USD"),
+			want: "max bid must be in BRL",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, nil).AnyTimes()
+
+			srv := vehicle.NewService(api, vehicle.WithMinIncrement(entity.NewMoney(10000, "")))
+
+			item, err := srv.SetMaxBid(ctx, 760, tt.user, tt.max)
+
+			assert.Nil(t, item)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestPlaceBid_LotState(t *testing.T) {
+	start := time.Date(2020, 8, 28, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	current := entity.Vehicle{
+		ID:  760,
+		Lot: entity.Lot{ID: "0068", VehicleLotID: "126845"},
+		Bid: entity.Bid{Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, nil).AnyTimes()
+
+	lots := lot.NewService(lot.WithClock(func() time.Time { return now }))
+	_, err := lots.Schedule(ctx, "0068", start, start.Add(time.Hour))
+	assert.Nil(t, err)
+
+	srv := vehicle.NewService(api, vehicle.WithLots(lots))
+
+	item, err := srv.PlaceBid(ctx, 760, "ALLBARBOS", entity.NewMoney(8000000, ""))
+
+	assert.Nil(t, item)
+	assert.Equal(t, handler.Conflict{Message: "lot 0068 is closed"}, err)
+
+	item, err = srv.SetMaxBid(ctx, 760, "ALLBARBOS", entity.NewMoney(8000000, ""))
+
+	assert.Nil(t, item)
+	assert.Equal(t, handler.Conflict{Message: "lot 0068 is closed"}, err)
+}
+
+func TestWrite_LotState(t *testing.T) {
+	start := time.Date(2020, 8, 28, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	closed := entity.Vehicle{ID: 760, Lot: entity.Lot{ID: "0068", VehicleLotID: "126845"}}
+	open := entity.Vehicle{ID: 761, Lot: entity.Lot{ID: "0196", VehicleLotID: "56248"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{closed, open}, nil).AnyTimes()
+
+	lots := lot.NewService(lot.WithClock(func() time.Time { return now }))
+	_, err := lots.Schedule(ctx, "0068", start, start.Add(time.Hour))
+	assert.Nil(t, err)
+
+	srv := vehicle.NewService(api, vehicle.WithLots(lots))
+
+	t.Run("must not move a vehicle out of a closed lot", func(t *testing.T) {
+		moved := closed
+		moved.Lot = entity.Lot{ID: "0196", VehicleLotID: "126845"}
+
+		err := srv.Update(ctx, &moved, "")
+
+		assert.Equal(t, handler.Conflict{Message: "lot 0068 is closed"}, err)
+	})
+
+	t.Run("must not move a vehicle into a closed lot", func(t *testing.T) {
+		moved := open
+		moved.Lot = entity.Lot{ID: "0068", VehicleLotID: "56248"}
+
+		err := srv.Update(ctx, &moved, "")
+
+		assert.Equal(t, handler.Conflict{Message: "lot 0068 is closed"}, err)
+	})
+
+	t.Run("must not delete a vehicle of a closed lot", func(t *testing.T) {
+		err := srv.Delete(ctx, 760, "")
+
+		assert.Equal(t, handler.Conflict{Message: "lot 0068 is closed"}, err)
+	})
+
+	t.Run("must not create a vehicle in a closed lot", func(t *testing.T) {
+		created, err := srv.Create(ctx, entity.Vehicle{Brand: "FIAT", Lot: entity.Lot{ID: "0068", VehicleLotID: "99999"}})
+
+		assert.Nil(t, created)
+		assert.Equal(t, handler.Conflict{Message: "lot 0068 is closed"}, err)
+	})
+}
+
+func TestPlaceBid_SoftClose(t *testing.T) {
+	start := time.Date(2020, 8, 28, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	now := end.Add(-time.Minute)
+	current := entity.Vehicle{
+		ID:  760,
+		Lot: entity.Lot{ID: "0068", VehicleLotID: "126845"},
+		Bid: entity.Bid{Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, nil).AnyTimes()
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	lots := lot.NewService(
+		lot.WithClock(func() time.Time { return now }),
+		lot.WithClosingWindow(5*time.Minute),
+		lot.WithSoftClose(2*time.Minute, 10*time.Minute),
+	)
+	_, err := lots.Schedule(ctx, "0068", start, end)
+	assert.Nil(t, err)
+
+	srv := vehicle.NewService(api, vehicle.WithLots(lots), vehicle.WithClock(func() time.Time { return now }))
+
+	_, err = srv.PlaceBid(ctx, 760, "ALLBARBOS", entity.NewMoney(8000000, ""))
+	assert.Nil(t, err)
+
+	state, _ := lots.ByID(ctx, "0068")
+	assert.Equal(t, end.Add(2*time.Minute), state.EndsAt)
+
+	updated := current
+	updated.Brand = "IVECO"
+	assert.Nil(t, srv.Update(ctx, &updated, ""))
+
+	state, _ = lots.ByID(ctx, "0068")
+	assert.Equal(t, end.Add(2*time.Minute), state.EndsAt, "must not extend without a new bid")
+}
+
+func TestUpdate_KeepsBid(t *testing.T) {
+	current := entity.Vehicle{
+		ID:  760,
+		Lot: entity.Lot{ID: "0068", VehicleLotID: "126845"},
+		Bid: entity.Bid{Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, nil).AnyTimes()
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, v *entity.Vehicle) error {
+		assert.Equal(t, current.Bid, v.Bid)
+		return nil
+	})
+
+	srv := vehicle.NewService(api)
+
+	updated := current
+	updated.Brand = "IVECO"
+	updated.Bid = entity.Bid{Value: entity.NewMoney(7500100, ""), User: "ALLBARBOS"}
+
+	assert.Nil(t, srv.Update(ctx, &updated, ""))
+	assert.Equal(t, current.Bid, updated.Bid, "must keep the stored bid, the bids go through PlaceBid")
+
+	page, err := srv.Bids(ctx, 760, vehicle.BidQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.Bid{current.Bid}, page.Items)
+}
+
+func TestCreate_DropsBid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{}, nil).AnyTimes()
+	api.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, v *entity.Vehicle) error {
+		assert.Equal(t, entity.Bid{}, v.Bid)
+		v.ID = 9999
+		return nil
+	})
+
+	created := v
+	created.Bid = entity.Bid{Date: time.Now(), Value: entity.NewMoney(7500100, ""), User: "ALLBARBOS"}
+
+	registered, err := vehicle.NewService(api).Create(ctx, created)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Bid{}, registered.Bid, "must create the vehicle without a bid, the bids go through PlaceBid")
+}
+
+func TestReserve(t *testing.T) {
+	now := time.Date(2020, 8, 28, 9, 30, 42, 0, time.UTC)
+	reserve := entity.NewMoney(8000000, "")
+	current := entity.Vehicle{
+		ID:  760,
+		Lot: entity.Lot{ID: "0196", VehicleLotID: "56248"},
+		Bid: entity.Bid{Date: now.Add(-time.Hour), Value: entity.NewMoney(7500000, ""), User: "ALDOBARROSO"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{current}, nil).AnyTimes()
+	api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	srv := vehicle.NewService(api, vehicle.WithClock(func() time.Time { return now }))
+
+	t.Run("must keep the reserve price out of the legacy vehicle", func(t *testing.T) {
+		updated := current
+		updated.Reserve = &reserve
+
+		err := srv.Update(ctx, &updated, "")
+
+		assert.Nil(t, err)
+		assert.False(t, updated.ReserveMet)
+
+		item, err := srv.ByID(ctx, 760)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &reserve, item.Reserve)
+		assert.False(t, item.ReserveMet)
+	})
+
+	t.Run("must filter by reserve met", func(t *testing.T) {
+		items, err := srv.All(ctx, []filters.Filter{filters.NewVehicleReserveMet(true)}, "")
+
+		assert.Nil(t, err)
+		assert.Empty(t, *items)
+	})
+
+	t.Run("must meet the reserve with a bid", func(t *testing.T) {
+		item, err := srv.PlaceBid(ctx, 760, "ALLBARBOS", entity.NewMoney(8000000, ""))
+
+		assert.Nil(t, err)
+		assert.True(t, item.ReserveMet)
+	})
+
+	t.Run("must keep the reserve price when the update leaves it out", func(t *testing.T) {
+		updated := current
+
+		err := srv.Update(ctx, &updated, "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, &reserve, updated.Reserve)
+	})
+
+	t.Run("must remove the reserve price when it is zero", func(t *testing.T) {
+		updated := current
+		updated.Reserve = &entity.Money{}
+
+		err := srv.Update(ctx, &updated, "")
+
+		assert.Nil(t, err)
+		assert.Nil(t, updated.Reserve)
+		assert.True(t, updated.ReserveMet)
+	})
+}
+
+func TestUnique(t *testing.T) {
+	taken := entity.Vehicle{ID: 760, Lot: entity.Lot{ID: "0196", VehicleLotID: "56248"}}
+	moved := entity.Vehicle{ID: 761, Lot: entity.Lot{ID: "0197", VehicleLotID: "56249"}}
+
+	testCases := []struct {
+		desc, want string
+		vehicle    entity.Vehicle
+		update     bool
+	}{
+		{
+			desc:    "must reject a new vehicle with a control code taken in the lot",
+			vehicle: entity.Vehicle{Lot: entity.Lot{ID: "0196", VehicleLotID: "56248"}},
+			want:    "vehicle lot id 56248 is already in lot 0196",
+		},
+		{
+			desc:    "must reject a vehicle moved to a control code taken in the lot",
+			vehicle: entity.Vehicle{ID: 761, Lot: entity.Lot{ID: "0196", VehicleLotID: "56248"}},
+			update:  true,
+			want:    "vehicle lot id 56248 is already in lot 0196",
+		},
+		{
+			desc:    "must keep the control code of the vehicle itself",
+			vehicle: taken,
+			update:  true,
+		},
+		{
+			desc:    "must take the control code in another lot",
+			vehicle: entity.Vehicle{ID: 761, Lot: entity.Lot{ID: "0197", VehicleLotID: "56248"}},
+			update:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			api := mock_legacy.NewMockAPI(ctrl)
+			api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{taken, moved}, nil).AnyTimes()
+
+			if tt.want == "" {
+				api.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			srv := vehicle.NewService(api)
+			var err error
+
+			if tt.update {
+				err = srv.Update(ctx, &tt.vehicle, "")
+			} else {
+				_, err = srv.Create(ctx, tt.vehicle)
+			}
+
+			if tt.want == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.IsType(t, handler.Conflict{}, err)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestByVehicleLotID(t *testing.T) {
+	testCases := []struct {
+		desc, lotID, vehicleLotID, want string
+		wantID                          int
+	}{
+		{desc: "must resolve the control code", lotID: "0196", vehicleLotID: "56248", wantID: 1},
+		{desc: "must return error when the control code is blank", lotID: "0196", want: "invalid vehicle lot id"},
+		{desc: "must return error when the control code is in another lot", lotID: "0033", vehicleLotID: "56248", want: "vehicle not found"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			item, err := vehicle.NewService(legacy.NewAPI()).ByVehicleLotID(ctx, tt.lotID, tt.vehicleLotID)
+
+			if tt.want != "" {
+				assert.Nil(t, item)
+				assert.EqualError(t, err, tt.want)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantID, item.ID)
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Run("must page by number", func(t *testing.T) {
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		page, err := vehicle.NewService(legacy.NewAPI()).ListByLot(ctx, "9999", "", vehicle.PageQuery{Page: paging.Page{Number: 4, Size: 20}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 68, page.Total)
+		assert.Equal(t, 4, page.Page)
+		assert.Len(t, page.Items, 8)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("must walk every vehicle by cursor", func(t *testing.T) {
+		srv := vehicle.NewService(legacy.NewAPI())
+		seen := map[int]bool{}
+		query := vehicle.PageQuery{Page: paging.Page{Size: 30}}
+
+		for pages := 0; pages < 10; pages++ {
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+			page, err := srv.ListByLot(ctx, "9999", "", query)
+
+			assert.Nil(t, err)
+
+			for _, v := range page.Items {
+				assert.False(t, seen[v.ID], "must not repeat vehicle %d", v.ID)
+				seen[v.ID] = true
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+
+			query.Cursor = page.NextCursor
+		}
+
+		assert.Len(t, seen, 68)
+	})
+}
+
+func TestList_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, want string
+		query      vehicle.PageQuery
+	}{
+		{desc: "must return error when page size is invalid", query: vehicle.PageQuery{Page: paging.Page{Size: -1}}, want: "page size is invalid"},
+		{desc: "must return error when cursor is invalid", query: vehicle.PageQuery{Cursor: "abc"}, want: "cursor is invalid"},
+		{desc: "must return error when page and cursor are used together", query: vehicle.PageQuery{Page: paging.Page{Number: 2}, Cursor: paging.Cursor{Offset: 1, Key: "1"}.Encode()}, want: "page and cursor cannot be used together"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+			page, err := vehicle.NewService(legacy.NewAPI()).List(ctx, nil, "", tt.query)
+
+			assert.Nil(t, page)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestBidsByUser(t *testing.T) {
+	t.Run("must group the top bids of the user by lot", func(t *testing.T) {
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		bids, err := vehicle.NewService(legacy.NewAPI()).BidsByUser(ctx, " LUSCAS ")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "LUSCAS", bids.User)
+		assert.Equal(t, 9, bids.Vehicles)
+		assert.Equal(t, entity.NewMoney(6810000, entity.DefaultCurrency), bids.Total)
+
+		lots := []string{}
+		for _, l := range bids.Lots {
+			lots = append(lots, l.LotID)
+		}
+
+		assert.Equal(t, []string{"0039", "0059", "0109", "0126", "0136", "0248", "9999"}, lots)
+		assert.Equal(t, []int{460, 486, 509}, []int{bids.Lots[6].Vehicles[0].ID, bids.Lots[6].Vehicles[1].ID, bids.Lots[6].Vehicles[2].ID})
+		assert.Equal(t, entity.NewMoney(1860000, entity.DefaultCurrency), bids.Lots[6].Total)
+	})
+
+	t.Run("must return no lots when the user holds no top bid", func(t *testing.T) {
+		mockApiLegacy("testdata/consultar_response_api.json", 200)
+
+		bids, err := vehicle.NewService(legacy.NewAPI()).BidsByUser(ctx, "nobody")
+
+		assert.Nil(t, err)
+		assert.Equal(t, &vehicle.UserBids{User: "nobody", Total: entity.NewMoney(0, entity.DefaultCurrency), Lots: []vehicle.LotBids{}}, bids)
+	})
+}
+
+func TestBidsByUser_Currencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bid := func(amount int64, currency string) entity.Bid {
+		return entity.Bid{User: "ana", Value: entity.NewMoney(amount, currency)}
+	}
+
+	api := mock_legacy.NewMockAPI(ctrl)
+	api.EXPECT().Get(gomock.Any()).Return([]entity.Vehicle{
+		{ID: 1, Lot: entity.Lot{ID: "0161"}, Bid: bid(100000, "")},
+		{ID: 2, Lot: entity.Lot{ID: "0161"}, Bid: bid(5000, "USD")},
+		{ID: 3, Lot: entity.Lot{ID: "0196"}, Bid: bid(2000, "EUR")},
+		{ID: 4, Lot: entity.Lot{ID: "0196"}, Bid: bid(3000, "USD")},
+		{ID: 5, Lot: entity.Lot{ID: "0196"}, Bid: bid(50000, "")},
+	}, nil)
+
+	bids, err := vehicle.NewService(api).BidsByUser(ctx, "ana")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5, bids.Vehicles)
+	assert.Equal(t, entity.NewMoney(150000, entity.DefaultCurrency), bids.Total, "must sum only the bids in the default currency")
+	assert.Equal(t, []entity.Money{entity.NewMoney(2000, "EUR"), entity.NewMoney(8000, "USD")}, bids.OtherTotals, "must sum the other bids by currency")
+	assert.Equal(t, []entity.Money{entity.NewMoney(5000, "USD")}, bids.Lots[0].OtherTotals)
+	assert.Equal(t, entity.NewMoney(50000, entity.DefaultCurrency), bids.Lots[1].Total)
+}
+
+func TestBidsByUser_Errors(t *testing.T) {
+	testCases := []struct {
+		desc, user, jsonPATH, want string
+	}{
+		{desc: "must return error when user is blank", user: " ", want: "invalid user"},
+		{desc: "must return error when legacy api fails", user: "luscas", jsonPATH: "testdata/consultar_response_error_api.json", want: "error when searching for vehicles in legacy api"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			mockApiLegacy(tt.jsonPATH, 200)
+
+			bids, err := vehicle.NewService(legacy.NewAPI()).BidsByUser(ctx, tt.user)
+
+			assert.Nil(t, bids)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}